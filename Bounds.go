@@ -0,0 +1,245 @@
+package sago
+
+import "math"
+
+// The tableau of an LP with bounds holds variables t that are related to the
+// variables x of the LP by x = offset + direction * t, where 0 <= t <= upper
+// unless the variable is free. Variables start out shifted to their lower
+// bound, or complemented at their upper bound if they have no lower bound, and
+// are complemented whenever they move between their bounds, so every nonbasic
+// variable is 0 in the tableau.
+type boundedColumns struct {
+	offset    []float64
+	direction []float64
+	upper     []float64
+	free      []bool
+}
+
+//SetBounds sets the bounds lower <= x <= upper of the variable at index col;
+//use math.Inf(-1) or math.Inf(1) for a missing bound. The tableau engine
+//handles bounded and free variables without adding constraints: a nonbasic
+//variable is at its lower or upper bound, or at 0 if it is free. LPs with
+//bounds are optimized with the tableau engine unless the engine is EXACT.
+func (lp *LP) SetBounds(col int, lower, upper float64) {
+	lp.reset()
+	if lp.bounds == nil {
+		lp.bounds = map[int]Range{}
+	}
+	lp.bounds[col] = Range{Lower: lower, Upper: upper}
+	if col+2 > lp.width {
+		lp.increaseWidth(col + 2)
+	}
+}
+
+//Bounds returns the lower and upper bound of the variable at index col. Variables
+//without bounds set are nonnegative.
+func (lp *LP) Bounds(col int) (float64, float64) {
+	if r, ok := lp.bounds[col]; ok {
+		return r.Lower, r.Upper
+	}
+	return 0, math.Inf(1)
+}
+
+// transforms the tableau as built into the variables t of the bounded-variable
+// simplex and negates the constraints whose b became negative. lp.bounded is
+// nil if every variable is nonnegative without upper bound. Returns false if
+// the lower bound of a variable exceeds its upper bound.
+func (lp *LP) applyBounds() bool {
+	lp.bounded = nil
+	n := lp.Width() - 1
+	b := newBoundedColumns(n)
+	bounded := false
+	tol := lp.GetTolerances()
+	for col, r := range lp.bounds {
+		if col >= n || r.Lower == 0 && math.IsInf(r.Upper, 1) {
+			continue
+		}
+		if Fgt(r.Lower, r.Upper, tol.PrimalFeasibility) {
+			return false
+		}
+		bounded = true
+		switch {
+		case !math.IsInf(r.Lower, -1):
+			b.offset[col] = r.Lower
+			b.upper[col] = r.Upper - r.Lower
+		case !math.IsInf(r.Upper, 1):
+			b.offset[col] = r.Upper
+			b.direction[col] = -1
+		default:
+			b.free[col] = true
+		}
+	}
+	if !bounded {
+		return true
+	}
+
+	// the objective function z + c1x1 + c2x2 + ...
+	row := lp.tableau[0]
+	for j := 1; j < len(row); j++ {
+		row[0] += row[j] * b.offset[j-1]
+		row[j] *= b.direction[j-1]
+	}
+	for _, row := range lp.tableau[1:] {
+		b.transform(row)
+		if Flt(row[0], 0, tol.Zero) {
+			ScalarVectorMultiply(-1, row)
+		}
+	}
+	lp.bounded = b
+	return true
+}
+
+func newBoundedColumns(n int) *boundedColumns {
+	b := &boundedColumns{}
+	b.extend(n)
+	return b
+}
+
+// adds nonnegative variables up to n variables
+func (b *boundedColumns) extend(n int) {
+	for len(b.offset) < n {
+		b.offset = append(b.offset, 0)
+		b.direction = append(b.direction, 1)
+		b.upper = append(b.upper, math.Inf(1))
+		b.free = append(b.free, false)
+	}
+}
+
+func (b *boundedColumns) copy() *boundedColumns {
+	if b == nil {
+		return nil
+	}
+	return &boundedColumns{
+		offset:    append([]float64{}, b.offset...),
+		direction: append([]float64{}, b.direction...),
+		upper:     append([]float64{}, b.upper...),
+		free:      append([]bool{}, b.free...),
+	}
+}
+
+// rewrites the constraint (b, a1, a2, ...) over x as a constraint over t
+func (b *boundedColumns) transform(row []float64) {
+	for j := 1; j < len(row) && j <= len(b.offset); j++ {
+		row[0] -= row[j] * b.offset[j-1]
+		row[j] *= b.direction[j-1]
+	}
+}
+
+// value of x_j for the value of t_j
+func (b *boundedColumns) value(j int, t float64) float64 {
+	if j >= len(b.offset) {
+		return t
+	}
+	return b.offset[j] + b.direction[j]*t
+}
+
+// substitutes t_j = upper_j - t'_j, or t_j = -t'_j if the variable is free, in
+// every row of the tableau. A nonbasic variable moves to its other bound; a
+// basic variable keeps its value and its row is negated to keep the tableau in
+// canonical form.
+func (lp *LP) complement(j int) {
+	u := lp.bounded.upper[j]
+	if lp.bounded.free[j] {
+		u = 0
+	}
+	for _, row := range lp.tableau {
+		complementColumn(row, j, u)
+	}
+	lp.bounded.offset[j] += lp.bounded.direction[j] * u
+	lp.bounded.direction[j] = -lp.bounded.direction[j]
+	for r, v := range lp.basis {
+		if v == j {
+			ScalarVectorMultiply(-1, lp.tableau[r+1])
+		}
+	}
+}
+
+func complementColumn(row []float64, j int, u float64) {
+	if row[j+1] == 0 {
+		return
+	}
+	row[0] -= row[j+1] * u
+	row[j+1] = -row[j+1]
+}
+
+// complements the free nonbasic variables with a positive entry in the row.
+// A free variable can enter the basis in either direction; in row 0 this makes
+// the variables that improve the objective function by decreasing improve it
+// by increasing.
+func (lp *LP) complementFreeColumns(row int) {
+	basic := make([]bool, len(lp.bounded.free))
+	for _, v := range lp.basis {
+		if v >= 0 && v < len(basic) {
+			basic[v] = true
+		}
+	}
+	tol := lp.GetTolerances()
+	for j, free := range lp.bounded.free {
+		if free && !basic[j] && Fgt(lp.tableau[row][j+1], 0, tol.Zero) {
+			lp.complement(j)
+		}
+	}
+}
+
+// ratio test of the bounded-variable simplex: the entering variable increases
+// until a basic variable decreases to 0 or increases to its upper bound, or
+// the entering variable reaches its own upper bound, in which case it is
+// complemented instead of entering the basis. Returns the variable that left
+// the basis, or -1 if there is none.
+func (lp *LP) boundedPivot(col int) int {
+	b := lp.bounded
+	row, toUpper := 0, false
+	low := b.upper[col-1]
+	tol := lp.GetTolerances()
+	for i, c := range lp.ListConstraints() {
+		v := lp.basis[i]
+		if v >= 0 && b.free[v] {
+			continue
+		}
+		a := c[col]
+		var ratio float64
+		switch {
+		case Fgt(a, 0, tol.Pivot):
+			ratio = c[0] / a
+		case Flt(a, 0, tol.Pivot) && v >= 0 && !math.IsInf(b.upper[v], 1):
+			ratio = (b.upper[v] - c[0]) / -a
+		default:
+			continue
+		}
+		ratio = math.Max(ratio, 0)
+		if Flt(ratio, low, tol.Zero) {
+			row, low, toUpper = i+1, ratio, a < 0
+		}
+	}
+
+	switch {
+	case row == 0 && math.IsInf(low, 1):
+		lp.setRay(col)
+		lp.unbounded = true
+		lp.solved = true
+		lp.optimal = false
+	case row == 0:
+		lp.complement(col - 1)
+		lp.trace.record(lp.tableau, 0, col)
+	default:
+		leaving := lp.basis[row-1]
+		if toUpper {
+			lp.complement(leaving)
+		}
+		lp.pivot(row, col)
+		lp.trace.record(lp.tableau, row, col)
+		return leaving
+	}
+	return -1
+}
+
+// complements the basic variables above their upper bound, which turns them
+// into basic variables below 0 for the dual simplex algorithm
+func (lp *LP) complementAboveUpper() {
+	tol := lp.GetTolerances()
+	for r, v := range lp.basis {
+		if v >= 0 && !lp.bounded.free[v] && Fgt(lp.tableau[r+1][0], lp.bounded.upper[v], tol.PrimalFeasibility) {
+			lp.complement(v)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// the LP with its bounds written as constraints over nonnegative variables:
+// x = lower + x' with x' <= upper - lower, x = upper - x' or x = x+ - x-
+func boundsAsConstraints(lp *LP) *LP {
+	n := lp.Width() - 1
+	slack := map[int]bool{}
+	for i := 0; i < lp.NumConstraints(); i++ {
+		slack[lp.SlackColumn(i)] = true
+	}
+	offset := make([]float64, n)
+	var columns [][]float64 // coefficient of each new variable in terms of x_j, by j
+	var upper []float64
+	for j := 0; j < n; j++ {
+		if slack[j] {
+			continue
+		}
+		lower, up := lp.Bounds(j)
+		switch {
+		case !math.IsInf(lower, -1):
+			offset[j] = lower
+			columns = append(columns, unitVector(j))
+			upper = append(upper, up-lower)
+		case !math.IsInf(up, 1):
+			offset[j] = up
+			columns = append(columns, ScalarVectorMultiply(-1, unitVector(j)))
+			upper = append(upper, math.Inf(1))
+		default:
+			columns = append(columns, unitVector(j), ScalarVectorMultiply(-1, unitVector(j)))
+			upper = append(upper, math.Inf(1), math.Inf(1))
+		}
+	}
+	// coefficients of the new variables in a row over x
+	substitute := func(row []float64) (float64, []float64) {
+		constant := 0.0
+		for j := 1; j < len(row) && j <= n; j++ {
+			constant += row[j] * offset[j-1]
+		}
+		coefs := make([]float64, len(columns))
+		for k, column := range columns {
+			for j, d := range column {
+				coefs[k] += d * row[j+1]
+			}
+		}
+		return constant, coefs
+	}
+
+	c := NewLP()
+	z, coefs := substitute(lp.tableau[0])
+	c.SetObjectiveFunction(lp.GetObjective(), lp.tableau[0][0]+z, coefs...)
+	for i, row := range lp.ListConstraints() {
+		row = ScalarVectorMultiply(lp.rowInfo(i).sign, append([]float64{}, row...))
+		constant, coefs := substitute(row)
+		b := row[0] - constant
+		switch lp.rowInfo(i).kind {
+		case constraintGeq:
+			c.AddConstraintGeq(b, coefs...)
+		case constraintLeq:
+			c.AddConstraintLeq(b, coefs...)
+		default:
+			c.AddConstraintEq(b, coefs...)
+		}
+	}
+	for k, u := range upper {
+		if !math.IsInf(u, 1) {
+			c.AddConstraintGeq(u, unitVector(k)...)
+		}
+	}
+	return c
+}
+
+func TestLP_SetBounds(t *testing.T) {
+	lp := NewLP()
+	lower, upper := lp.Bounds(1)
+	assert.Equal(t, []float64{0, math.Inf(1)}, []float64{lower, upper})
+
+	// max x + y st. x + 2y <= 10, -2 <= x <= 3, y free
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(10, 1, 2)
+	lp.SetBounds(0, -2, 3)
+	lp.SetBounds(1, math.Inf(-1), math.Inf(1))
+	lower, upper = lp.Bounds(0)
+	assert.Equal(t, []float64{-2, 3}, []float64{lower, upper})
+	lp.Optimize()
+	assert.Equal(t, 1, lp.NumConstraints())
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 6.5, lp.ObjectiveValue(), EPSILON)
+	sol, err := lp.Solution()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{3, 3.5, 0}, sol, EPSILON)
+
+	// x <= -1 moves the optimum to the negative part of the box
+	lp.SetBounds(0, -2, -1)
+	lp.Optimize()
+	assert.InDelta(t, 4.5, lp.ObjectiveValue(), EPSILON)
+	sol, _ = lp.Solution()
+	assert.InDeltaSlice(t, []float64{-1, 5.5, 0}, sol, EPSILON)
+
+	// 2 <= x <= 1 is infeasible
+	lp.SetBounds(0, 2, 1)
+	assert.False(t, lp.Feasible())
+	lp.Optimize()
+	assert.True(t, lp.Solved())
+	_, err = lp.Solution()
+	assert.Error(t, err)
+
+	// the revised engine leaves LPs with bounds to the tableau engine
+	lp.SetBounds(0, 0, math.Inf(1))
+	lp.SetBounds(1, -1, 4)
+	lp.SetEngine(REVISED)
+	lp.Optimize()
+	assert.InDelta(t, 11, lp.ObjectiveValue(), EPSILON)
+	sol, _ = lp.Solution()
+	assert.InDeltaSlice(t, []float64{12, -1, 0}, sol, EPSILON)
+
+	// max x - y st. x + 2y <= 10 with y free is unbounded
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, -1)
+	lp.AddConstraintGeq(10, 1, 2)
+	lp.SetBounds(1, math.Inf(-1), math.Inf(1))
+	lp.Optimize()
+	assert.False(t, lp.Bounded())
+}
+
+func TestLP_OptimizeBoundedRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for k := 0; k < 20; k++ {
+		lp := randomLP(r, 8, 12)
+		for j := 0; j < 12; j++ {
+			lower := float64(r.Intn(11) - 5)
+			upper := lower + float64(r.Intn(10))
+			switch r.Intn(6) {
+			case 0:
+				lower = math.Inf(-1)
+			case 1:
+				lower, upper = math.Inf(-1), math.Inf(1)
+			case 2:
+				upper = math.Inf(1)
+			case 3:
+				continue
+			}
+			if math.IsInf(lower, -1) {
+				// x_j >= -10 as a constraint keeps the LP bounded
+				lp.AddConstraintLeq(-10, unitVector(j)...)
+			}
+			lp.SetBounds(j, lower, upper)
+		}
+		expected := boundsAsConstraints(lp)
+		expected.Optimize()
+		lp.Optimize()
+		assert.Equal(t, expected.Feasible(), lp.Feasible())
+		assert.Equal(t, expected.Bounded(), lp.Bounded())
+		if !expected.Optimal() || !expected.Feasible() || !expected.Bounded() {
+			continue
+		}
+		assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6)
+
+		sol, err := lp.Solution()
+		assert.NoError(t, err)
+		for j := 0; j < 12; j++ {
+			lower, upper := lp.Bounds(j)
+			assert.True(t, Fle(lower, sol[j], 1e-9) && Fle(sol[j], upper, 1e-9))
+		}
+		for _, row := range lp.original[1:] {
+			lhs := 0.0
+			for j, x := range sol {
+				lhs += row[j+1] * x
+			}
+			assert.InDelta(t, row[0], lhs, 1e-6)
+		}
+	}
+}
+
+func TestLP_OptimizeBoundedWarmStart(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for k := 0; k < 10; k++ {
+		lp := randomLP(r, 5, 8)
+		for j := 0; j < 8; j++ {
+			lp.SetBounds(j, float64(r.Intn(3)-1), float64(r.Intn(5)+2))
+		}
+		lp.Optimize()
+		assert.True(t, lp.Optimal())
+
+		// x_j <= 1 and x_j >= 0 for some columns, warm started
+		cold := lp.Copy()
+		cold.reset()
+		for j := 0; j < 8; j += 3 {
+			lp.AddConstraintGeq(1, unitVector(j)...)
+			lp.AddConstraintLeq(0, unitVector(j+1)...)
+			cold.AddConstraintGeq(1, unitVector(j)...)
+			cold.AddConstraintLeq(0, unitVector(j+1)...)
+		}
+		lp.Optimize()
+		cold.Optimize()
+		assert.Equal(t, cold.Feasible(), lp.Feasible())
+		assert.InDelta(t, cold.ObjectiveValue(), lp.ObjectiveValue(), 1e-6)
+	}
+}
+
+func TestLP_OptimizeMIPBounded(t *testing.T) {
+	// integerLP with -3 <= x1 <= 3 and x2 <= 1
+	lp := integerLP()
+	lp.SetBounds(0, -3, 3)
+	lp.SetBounds(1, 0, 1)
+	result, err := lp.OptimizeMIP()
+	assert.NoError(t, err)
+	assert.InDelta(t, 4, result.ObjectiveValue, 1e-6)
+	assert.InDeltaSlice(t, []float64{3, 1}, result.Solution[:2], 1e-6)
+
+	lp = integerLP()
+	lp.SetBounds(0, -1.5, 2.5)
+	lp.Optimize()
+	cuts, err := lp.GomoryCuts()
+	assert.NoError(t, err)
+	sol, _ := lp.Solution()
+	for _, cut := range cuts {
+		lhs := 0.0
+		for j, x := range sol {
+			lhs += cut[j+1] * x
+		}
+		assert.Less(t, lhs, cut[0])
+	}
+}
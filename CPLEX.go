@@ -0,0 +1,567 @@
+package sago
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	lpTokenName = iota
+	lpTokenNumber
+	lpTokenSign     // + or -
+	lpTokenRelation // <=, >= or =
+	lpTokenColon
+)
+
+// a token of the CPLEX LP format
+type lpToken struct {
+	kind  int
+	text  string
+	value float64
+	line  int
+}
+
+// the tokens of a section of a CPLEX LP file
+type lpSection struct {
+	name   string // "maximize", "minimize", "subject to", "bounds", "general", "binary"
+	line   int
+	tokens []lpToken
+}
+
+// state of ReadCPLEX
+type lpParser struct {
+	model  *Model
+	vars   map[string]*Var
+	tokens []lpToken
+	pos    int
+	line   int // line of the last token, for errors at the end of a section
+}
+
+// keywords that start a section, by their first word
+var lpSections = map[string]string{
+	"maximize": "maximize", "maximum": "maximize", "max": "maximize",
+	"minimize": "minimize", "minimum": "minimize", "min": "minimize",
+	"subject": "subject to", "such": "subject to", "st": "subject to", "s.t.": "subject to",
+	"bounds": "bounds", "bound": "bounds",
+	"general": "general", "generals": "general", "gen": "general",
+	"binary": "binary", "binaries": "binary", "bin": "binary",
+	"end": "end",
+}
+
+//ReadCPLEX reads a model in the CPLEX LP format, i.e. an objective section
+//(Maximize or Minimize) followed by the optional Subject To, Bounds, General
+//and Binary sections and End, each keyword alone on its line. A backslash
+//comments out the rest of the line and \* ... *\ comments out everything in
+//between. Variables are created in the order they first appear. Returns an InvalidInputError with the line number if the input cannot
+//be parsed.
+func ReadCPLEX(r io.Reader) (*Model, error) {
+	sections, err := scanCPLEX(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 || sections[0].name != "maximize" && sections[0].name != "minimize" {
+		line := 1
+		if len(sections) > 0 {
+			line = sections[0].line
+		}
+		return nil, InvalidInputError{s: "expected Maximize or Minimize section", Line: line}
+	}
+
+	p := &lpParser{model: NewModel(), vars: map[string]*Var{}}
+	for i, section := range sections {
+		p.tokens, p.pos, p.line = section.tokens, 0, section.line
+		switch section.name {
+		case "maximize", "minimize":
+			if i > 0 {
+				return nil, p.error("objective section must come first")
+			}
+			err = p.parseObjective(section.name == "maximize")
+		case "subject to":
+			err = p.parseConstraints()
+		case "bounds":
+			err = p.parseBounds()
+		case "general", "binary":
+			err = p.parseMarks(section.name == "binary")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.model, nil
+}
+
+// splits the input into sections of tokens, dropping comments. A keyword
+// starts a section only when it stands alone on its line, so that constraints
+// may continue on lines that start with a variable named like a keyword.
+func scanCPLEX(r io.Reader) ([]lpSection, error) {
+	var sections []lpSection
+	scanner := bufio.NewScanner(r)
+	line := 0
+	comment := 0 // line of the unterminated block comment, if any
+	for scanner.Scan() {
+		line++
+		text := stripCPLEXComments(scanner.Text(), line, &comment)
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		keyword := strings.ToLower(fields[0])
+		name, ok := lpSections[keyword]
+		if keyword == "subject" || keyword == "such" {
+			ok = len(fields) == 2 && (strings.EqualFold(fields[1], "to") || strings.EqualFold(fields[1], "that"))
+			if !ok && len(fields) <= 2 {
+				return nil, InvalidInputError{s: fmt.Sprintf("unknown section %q", text), Line: line}
+			}
+		} else {
+			ok = ok && len(fields) == 1
+		}
+		if ok && name == "end" {
+			break
+		}
+		if ok {
+			sections = append(sections, lpSection{name: name, line: line})
+			continue
+		}
+		if len(sections) == 0 {
+			return nil, InvalidInputError{s: "expected Maximize or Minimize section", Line: line}
+		}
+		tokens, err := tokenizeCPLEX(text, line)
+		if err != nil {
+			return nil, err
+		}
+		last := &sections[len(sections)-1]
+		last.tokens = append(last.tokens, tokens...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if comment > 0 {
+		return nil, InvalidInputError{s: "unterminated comment", Line: comment}
+	}
+	return sections, nil
+}
+
+// the text of a line without its comments: a backslash comments out the rest
+// of the line, and \* ... *\ comments out everything in between, possibly over
+// several lines. comment is the line where the block comment the text starts
+// in opened, or 0, and is updated for the next line.
+func stripCPLEXComments(text string, line int, comment *int) string {
+	var b strings.Builder
+	for text != "" {
+		if *comment > 0 {
+			i := strings.Index(text, `*\`)
+			if i < 0 {
+				break
+			}
+			*comment = 0
+			text = text[i+2:]
+			b.WriteByte(' ')
+			continue
+		}
+		i := strings.Index(text, `\`)
+		if i < 0 {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString(text[:i])
+		if !strings.HasPrefix(text[i:], `\*`) {
+			break
+		}
+		*comment = line
+		text = text[i+2:]
+	}
+	return b.String()
+}
+
+func tokenizeCPLEX(text string, line int) ([]lpToken, error) {
+	var tokens []lpToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '+' || c == '-':
+			tokens = append(tokens, lpToken{kind: lpTokenSign, text: text[i : i+1], line: line})
+			i++
+		case c == ':':
+			tokens = append(tokens, lpToken{kind: lpTokenColon, text: ":", line: line})
+			i++
+		case c == '<' || c == '>' || c == '=':
+			j := i + 1
+			for j < len(text) && strings.IndexByte("<>=", text[j]) >= 0 {
+				j++
+			}
+			relation := map[string]string{"<": "<=", "<=": "<=", "=<": "<=", ">": ">=", ">=": ">=", "=>": ">=", "=": "=", "==": "="}[text[i:j]]
+			if relation == "" {
+				return nil, InvalidInputError{s: fmt.Sprintf("unknown relation %q", text[i:j]), Line: line}
+			}
+			tokens = append(tokens, lpToken{kind: lpTokenRelation, text: relation, line: line})
+			i = j
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9':
+			j := i
+			for j < len(text) && (text[j] >= '0' && text[j] <= '9' || text[j] == '.') {
+				j++
+			}
+			if j < len(text) && (text[j] == 'e' || text[j] == 'E') {
+				k := j + 1
+				if k < len(text) && (text[k] == '+' || text[k] == '-') {
+					k++
+				}
+				if k < len(text) && text[k] >= '0' && text[k] <= '9' {
+					for j = k; j < len(text) && text[j] >= '0' && text[j] <= '9'; j++ {
+					}
+				}
+			}
+			v, err := strconv.ParseFloat(text[i:j], 64)
+			if err != nil {
+				return nil, InvalidInputError{s: fmt.Sprintf("invalid number %q", text[i:j]), Line: line}
+			}
+			tokens = append(tokens, lpToken{kind: lpTokenNumber, text: text[i:j], value: v, line: line})
+			i = j
+		default:
+			j := i
+			for j < len(text) && strings.IndexByte(" \t+-:<>=", text[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, lpToken{kind: lpTokenName, text: text[i:j], line: line})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func (p *lpParser) error(format string, a ...interface{}) error {
+	line := p.line
+	if p.pos < len(p.tokens) {
+		line = p.tokens[p.pos].line
+	} else if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+	return InvalidInputError{s: fmt.Sprintf(format, a...), Line: line}
+}
+
+// returns the next token if it is of the given kind
+func (p *lpParser) accept(kind int) (lpToken, bool) {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind {
+		p.pos++
+		return p.tokens[p.pos-1], true
+	}
+	return lpToken{}, false
+}
+
+func (p *lpParser) variable(name string) *Var {
+	v, ok := p.vars[name]
+	if !ok {
+		v = p.model.NewVar(name)
+		p.vars[name] = v
+	}
+	return v
+}
+
+// skips the label "name:" of an objective function or constraint
+func (p *lpParser) label() string {
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos].kind == lpTokenName && p.tokens[p.pos+1].kind == lpTokenColon {
+		p.pos += 2
+		return p.tokens[p.pos-2].text
+	}
+	return ""
+}
+
+// parses terms [+|-] [number] [name] until a token that cannot continue the expression
+func (p *lpParser) parseExpr() (Expr, error) {
+	e := Constant(0)
+	for first := true; ; first = false {
+		start := p.pos
+		sign := 1.0
+		for {
+			t, ok := p.accept(lpTokenSign)
+			if !ok {
+				break
+			}
+			if t.text == "-" {
+				sign = -sign
+			}
+		}
+		if !first && p.pos == start {
+			return e, nil
+		}
+		num, isNum := p.accept(lpTokenNumber)
+		name, isName := lpToken{}, false
+		if p.pos+1 >= len(p.tokens) || p.tokens[p.pos+1].kind != lpTokenColon {
+			name, isName = p.accept(lpTokenName)
+		}
+		switch {
+		case isName && isNum:
+			e.terms[p.variable(name.text)] += sign * num.value
+		case isName:
+			e.terms[p.variable(name.text)] += sign
+		case isNum:
+			e.constant += sign * num.value
+		default:
+			return e, p.error("expected a number or a variable")
+		}
+	}
+}
+
+// parses [+|-] number
+func (p *lpParser) parseNumber() (float64, error) {
+	sign := 1.0
+	if t, ok := p.accept(lpTokenSign); ok && t.text == "-" {
+		sign = -1
+	}
+	if t, ok := p.accept(lpTokenNumber); ok {
+		return sign * t.value, nil
+	}
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == lpTokenName {
+		switch strings.ToLower(p.tokens[p.pos].text) {
+		case "inf", "infinity":
+			p.pos++
+			return sign * math.Inf(1), nil
+		}
+	}
+	return 0, p.error("expected a number")
+}
+
+func (p *lpParser) parseObjective(maximize bool) error {
+	p.label()
+	if p.pos == len(p.tokens) {
+		// no objective function
+		p.setObjective(maximize, Constant(0))
+		return nil
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return err
+	}
+	if p.pos < len(p.tokens) {
+		return p.error("unexpected %q in objective function", p.tokens[p.pos].text)
+	}
+	p.setObjective(maximize, e)
+	return nil
+}
+
+func (p *lpParser) setObjective(maximize bool, e Expr) {
+	if maximize {
+		p.model.Maximize(e)
+	} else {
+		p.model.Minimize(e)
+	}
+}
+
+func (p *lpParser) parseConstraints() error {
+	for p.pos < len(p.tokens) {
+		name := p.label()
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		relation, ok := p.accept(lpTokenRelation)
+		if !ok {
+			return p.error("expected <=, >= or =")
+		}
+		rhs, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+		var c Constraint
+		switch relation.text {
+		case "<=":
+			c = e.Leq(rhs)
+		case ">=":
+			c = e.Geq(rhs)
+		default:
+			c = e.Eq(rhs)
+		}
+		p.model.Add(c.Named(name))
+	}
+	return nil
+}
+
+// parses bounds of the forms "x free", "x op value", "value op x" and
+// "value op x op value"
+func (p *lpParser) parseBounds() error {
+	for p.pos < len(p.tokens) {
+		if p.pos+1 < len(p.tokens) && p.tokens[p.pos].kind == lpTokenName && strings.ToLower(p.tokens[p.pos+1].text) == "free" {
+			p.variable(p.tokens[p.pos].text).SetBounds(math.Inf(-1), math.Inf(1))
+			p.pos += 2
+			continue
+		}
+
+		var v *Var
+		if t, ok := p.accept(lpTokenName); ok && !strings.EqualFold(t.text, "inf") && !strings.EqualFold(t.text, "infinity") {
+			v = p.variable(t.text)
+		} else {
+			if ok {
+				p.pos--
+			}
+			value, err := p.parseNumber()
+			if err != nil {
+				return err
+			}
+			relation, ok := p.accept(lpTokenRelation)
+			if !ok {
+				return p.error("expected <=, >= or =")
+			}
+			name, ok := p.accept(lpTokenName)
+			if !ok {
+				return p.error("expected a variable")
+			}
+			v = p.variable(name.text)
+			// value op x is x op' value
+			setBound(v, map[string]string{"<=": ">=", ">=": "<=", "=": "="}[relation.text], value)
+			if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != lpTokenRelation {
+				continue
+			}
+		}
+
+		relation, ok := p.accept(lpTokenRelation)
+		if !ok {
+			return p.error("expected <=, >= or =")
+		}
+		value, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+		setBound(v, relation.text, value)
+	}
+	return nil
+}
+
+// applies the bound v op value
+func setBound(v *Var, relation string, value float64) {
+	lower, upper := v.lower, v.upper
+	switch relation {
+	case "<=":
+		upper = value
+	case ">=":
+		lower = value
+	default:
+		lower, upper = value, value
+	}
+	v.SetBounds(lower, upper)
+}
+
+// parses the names of the General or Binary section
+func (p *lpParser) parseMarks(binary bool) error {
+	for p.pos < len(p.tokens) {
+		t, ok := p.accept(lpTokenName)
+		if !ok {
+			return p.error("expected a variable")
+		}
+		if binary {
+			p.variable(t.text).SetBinary()
+		} else {
+			p.variable(t.text).SetInteger()
+		}
+	}
+	return nil
+}
+
+//WriteCPLEX writes the model in the CPLEX LP format. Every variable appears in
+//the objective function, with coefficient 0 if need be, so that reading the
+//output creates the variables in the same order. Constraints without a name
+//are named c1, c2, ... by their position.
+func (m *Model) WriteCPLEX(w io.Writer) error {
+	b := &strings.Builder{}
+	if m.sense == MAXIMIZE {
+		b.WriteString("Maximize\n")
+	} else {
+		b.WriteString("Minimize\n")
+	}
+	b.WriteString(" obj:")
+	for i, v := range m.vars {
+		b.WriteString(formatTerm(m.objective.terms[v], v.name, i == 0))
+	}
+	if c := m.objective.constant; c != 0 || len(m.vars) == 0 {
+		b.WriteString(formatTerm(c, "", len(m.vars) == 0))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Subject To\n")
+	for i, c := range m.constraints {
+		fmt.Fprintf(b, " %s:", m.constraintName(i))
+		first := true
+		for _, v := range m.vars {
+			if a := c.expr.terms[v]; a != 0 {
+				b.WriteString(formatTerm(a, v.name, first))
+				first = false
+			}
+		}
+		if first {
+			b.WriteString(" 0")
+		}
+		relation := map[int]string{relationEq: "=", relationLeq: "<=", relationGeq: ">="}[c.relation]
+		fmt.Fprintf(b, " %s %s\n", relation, formatLP(c.rhs-c.expr.constant))
+	}
+
+	var bounds, generals, binaries []string
+	for _, v := range m.vars {
+		switch {
+		case v.binary:
+			binaries = append(binaries, v.name)
+			continue
+		case v.integer:
+			generals = append(generals, v.name)
+		}
+		switch {
+		case v.lower == v.upper:
+			bounds = append(bounds, fmt.Sprintf("%s = %s", v.name, formatLP(v.lower)))
+		case math.IsInf(v.lower, -1) && math.IsInf(v.upper, 1):
+			bounds = append(bounds, v.name+" free")
+		case v.lower == 0 && math.IsInf(v.upper, 1):
+		case v.lower == 0:
+			bounds = append(bounds, fmt.Sprintf("%s <= %s", v.name, formatLP(v.upper)))
+		case math.IsInf(v.upper, 1):
+			bounds = append(bounds, fmt.Sprintf("%s >= %s", v.name, formatLP(v.lower)))
+		default:
+			bounds = append(bounds, fmt.Sprintf("%s <= %s <= %s", formatLP(v.lower), v.name, formatLP(v.upper)))
+		}
+	}
+	for _, section := range []struct {
+		name  string
+		lines []string
+	}{{"Bounds", bounds}, {"General", generals}, {"Binary", binaries}} {
+		if len(section.lines) > 0 {
+			b.WriteString(section.name + "\n")
+			for _, line := range section.lines {
+				b.WriteString(" " + line + "\n")
+			}
+		}
+	}
+	b.WriteString("End\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formats the term a * name, preceded by its sign unless it is the first term
+// and positive; an empty name is a constant term
+func formatTerm(a float64, name string, first bool) string {
+	sign := " +"
+	if a < 0 {
+		sign = " -"
+	} else if first {
+		sign = ""
+	}
+	coef := formatLP(math.Abs(a))
+	switch {
+	case name == "":
+		return sign + " " + coef
+	case coef == "1":
+		return sign + " " + name
+	}
+	return sign + " " + coef + " " + name
+}
+
+func formatLP(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
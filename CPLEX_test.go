@@ -0,0 +1,213 @@
+package sago
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readCPLEX(t *testing.T, testName string) *Model {
+	f, err := os.Open("Tests/" + testName)
+	assert.NoError(t, err)
+	defer f.Close()
+	m, err := ReadCPLEX(f)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestReadCPLEX(t *testing.T) {
+	m := readCPLEX(t, "CPLEX_example.lp")
+	var names []string
+	for _, v := range m.Vars() {
+		names = append(names, v.Name())
+	}
+	assert.Equal(t, []string{"x", "y", "z"}, names)
+	assert.Equal(t, "lim3", m.Constraints()[2].Name())
+	assert.Equal(t, "", m.Constraints()[3].Name())
+	assert.True(t, m.Var("y").IsInteger())
+	assert.False(t, m.Var("y").IsBinary())
+	assert.True(t, m.Var("z").IsBinary())
+
+	lp, err := m.LP()
+	assert.NoError(t, err)
+	expected := tableau{
+		{2, 3, 5, 1, 0, 0, 0, 0},
+		{4, 1, 0, 0, 1, 0, 0, 0},
+		{12, 0, 2, 0, 0, 1, 0, 0},
+		{18, 3, 2, 0, 0, 0, 1, 0},
+		{12, 3, 2, 0, 0, 0, 0, -1},
+	}
+	assert.Equal(t, expected, lp.tableau)
+	lower, upper := lp.Bounds(0)
+	assert.Equal(t, []float64{0, 10}, []float64{lower, upper})
+	result, err := lp.OptimizeMIP()
+	assert.NoError(t, err)
+	assert.InDelta(t, 39, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, []float64{2, 6, 1}, result.Solution[:3], EPSILON)
+}
+
+func TestReadCPLEX_Bounds(t *testing.T) {
+	input := `Minimize
+ a + b + c + d + e + f
+Bounds
+ a free
+ -inf <= b <= 5
+ 2 <= c
+ d <= 8
+ e = 3
+ 1 <= f <= 4
+End`
+	m, err := ReadCPLEX(strings.NewReader(input))
+	assert.NoError(t, err)
+	bounds := [][2]float64{
+		{math.Inf(-1), math.Inf(1)},
+		{math.Inf(-1), 5},
+		{2, math.Inf(1)},
+		{0, 8},
+		{3, 3},
+		{1, 4},
+	}
+	for i, v := range m.Vars() {
+		lower, upper := v.Bounds()
+		assert.Equal(t, bounds[i], [2]float64{lower, upper}, v.Name())
+	}
+	lp, err := m.LP()
+	assert.NoError(t, err)
+	lower, upper := lp.Bounds(1)
+	assert.Equal(t, []float64{math.Inf(-1), 5}, []float64{lower, upper})
+}
+
+func TestReadCPLEX_Minimize(t *testing.T) {
+	input := `Minimize
+ cost: 2 x + 3 y + 1
+Subject To
+ demand: x + y >= 4
+Bounds
+ x <= 3
+End`
+	m, err := ReadCPLEX(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.NoError(t, m.Optimize())
+	z, err := m.ObjectiveValue()
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, z, EPSILON)
+	solution, err := m.Solution()
+	assert.NoError(t, err)
+	assert.InDelta(t, 3, solution["x"], EPSILON)
+	assert.InDelta(t, 1, solution["y"], EPSILON)
+}
+
+func TestReadCPLEX_Keywords(t *testing.T) {
+	// the constraint continues on the lines that start with the variables
+	// named like keywords, which start a section only alone on their line
+	input := `\* variables named max, st and bin
+   over two lines *\ Maximize
+ max + st \* in a line *\ + bin
+Subject To
+ c1: 2 max
+ + st +
+ bin <= 3
+ max + bin \* at the end *\
+ >= 1
+Binary
+ max bin
+End`
+	m, err := ReadCPLEX(strings.NewReader(input))
+	assert.NoError(t, err)
+	var names []string
+	for _, v := range m.Vars() {
+		names = append(names, v.Name())
+	}
+	assert.Equal(t, []string{"max", "st", "bin"}, names)
+	assert.Equal(t, 2, len(m.Constraints()))
+	assert.True(t, m.Var("max").IsBinary())
+	assert.True(t, m.Var("bin").IsBinary())
+
+	lp, err := m.LP()
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{3, 2, 1, 1, 1, 0}, lp.tableau[1])
+	assert.Equal(t, []float64{1, 1, 0, 1, 0, -1}, lp.tableau[2])
+}
+
+func TestReadCPLEX_Errors(t *testing.T) {
+	tests := []struct {
+		lp   string
+		line int
+	}{
+		{"Subject To\n x <= 1\nEnd\n", 1},
+		{"x + y\n", 1},
+		{"Maximize\n x + y\nSubject To\n x + y\n\n", 4},
+		{"Maximize\n x + y\nSubject To\n c1: x + y <= \nEnd\n", 4},
+		{"Maximize\n x + y\nSubject To\n c1: x + y <= 1\n c2: x <> 2\nEnd\n", 5},
+		{"Maximize\n x y\nEnd\n", 2},
+		{"Maximize\n x\nBounds\n x <= y\nEnd\n", 4},
+		{"Maximize\n x\nGeneral\n 1\nEnd\n", 4},
+		{"Maximize\n x\nSubject\n x <= 1\nEnd\n", 3},
+		{"Maximize x\nEnd\n", 1},
+		{"Maximize\n x\n\\* not closed\nEnd\n", 3},
+	}
+	for _, test := range tests {
+		_, err := ReadCPLEX(strings.NewReader(test.lp))
+		if assert.IsType(t, InvalidInputError{}, err, test.lp) {
+			assert.Equal(t, test.line, err.(InvalidInputError).Line, test.lp)
+		}
+	}
+}
+
+func TestModel_WriteCPLEX(t *testing.T) {
+	m := readCPLEX(t, "CPLEX_example.lp")
+	buf := &bytes.Buffer{}
+	assert.NoError(t, m.WriteCPLEX(buf))
+	expected := `Maximize
+ obj: 3 x + 5 y + z + 2
+Subject To
+ lim1: x <= 4
+ lim2: 2 y <= 12
+ lim3: 3 x + 2 y <= 18
+ c4: 3 x + 2 y >= 12
+Bounds
+ x <= 10
+General
+ y
+Binary
+ z
+End
+`
+	assert.Equal(t, expected, buf.String())
+
+	// the output reads back into the same model
+	read, err := ReadCPLEX(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	again := &bytes.Buffer{}
+	assert.NoError(t, read.WriteCPLEX(again))
+	assert.Equal(t, expected, again.String())
+
+	m = NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	z := m.NewVar("z")
+	z.SetBounds(-1, 2.5)
+	m.Minimize(y.Mul(-1).PlusConstant(-1))
+	m.Add(x.Mul(-2).Plus(y).PlusConstant(1).Eq(-3))
+	m.Add(Constant(0).Geq(0).Named("empty"))
+	buf.Reset()
+	assert.NoError(t, m.WriteCPLEX(buf))
+	expected = `Minimize
+ obj: 0 x - y + 0 z - 1
+Subject To
+ c1: - 2 x + y = -4
+ empty: 0 >= 0
+Bounds
+ -1 <= z <= 2.5
+End
+`
+	assert.Equal(t, expected, buf.String())
+	read, err = ReadCPLEX(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	again.Reset()
+	assert.NoError(t, read.WriteCPLEX(again))
+	assert.Equal(t, expected, again.String())
+}
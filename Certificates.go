@@ -0,0 +1,77 @@
+package sago
+
+import "math"
+
+// reads the Farkas certificate of an infeasible LP off the objective function of
+// its optimal auxiliary LP A. Row 0 of A is (0, 1, ..., 1) minus a combination
+// λ of the constraints, so the coefficient of the k-th artificial variable is
+// 1 - λ_k, and λ proves infeasibility: -λᵀA >= 0 is the nonnegative row 0
+// over the variables of the LP and -λᵀb < 0 its negated optimal value.
+func (lp *LP) farkasVector(A *LP) []float64 {
+	variables := len(lp.tableau[0])
+	y := make([]float64, lp.NumConstraints())
+	for i := range y {
+		// the rows of the tableau are the constraints as added times their sign
+		y[i] = (A.tableau[0][variables+i] - 1) * lp.rowInfo(i).sign
+	}
+	return y
+}
+
+// the error for an infeasible LP. The certificate of an LP that was found
+// infeasible by the dual simplex algorithm comes from a phase I from scratch.
+func (lp *LP) infeasibleError() InfeasibleError {
+	if lp.farkas == nil && lp.dirty && !lp.hasBounds() {
+		c := lp.Copy()
+		c.reset()
+		c.Feasible()
+		lp.farkas = c.farkas
+	}
+	return InfeasibleError{s: "LP is infeasible", Farkas: append([]float64(nil), lp.farkas...)}
+}
+
+// true if a variable has bounds other than x >= 0
+func (lp *LP) hasBounds() bool {
+	for _, r := range lp.bounds {
+		if r.Lower != 0 || r.Upper != math.Inf(1) {
+			return true
+		}
+	}
+	return false
+}
+
+// records the ray along which the entering variable col of the tableau grows
+// without limit: the basic variables change by minus the entries of its column
+func (lp *LP) setRay(col int) {
+	lp.ray = make([]float64, lp.Width()-1)
+	lp.ray[col-1] = 1
+	for r, v := range lp.basis {
+		if v >= 0 {
+			lp.ray[v] = -lp.tableau[r+1][col]
+		}
+	}
+}
+
+// the error for an unbounded LP with the current basic solution and the ray
+// over the variables of the LP
+func (lp *LP) unboundedError() UnboundedError {
+	e := UnboundedError{s: "LP is unbounded", Entering: -1}
+	if lp.ray == nil {
+		return e
+	}
+	e.Point = lp.basicSolution()
+	e.Ray = append([]float64{}, lp.ray...)
+	basic := map[int]bool{}
+	for _, v := range lp.basis {
+		basic[v] = true
+	}
+	for j := range e.Ray {
+		if lp.bounded != nil && j < len(lp.bounded.direction) {
+			e.Ray[j] *= lp.bounded.direction[j]
+		}
+		if !basic[j] && e.Ray[j] != 0 {
+			// the only nonbasic variable that moves
+			e.Entering = j
+		}
+	}
+	return e
+}
@@ -0,0 +1,179 @@
+package sago
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checks yᵀA >= 0 and yᵀb < 0 over the constraints as added
+func assertFarkas(t *testing.T, lp *LP, err error) {
+	if !assert.IsType(t, InfeasibleError{}, err) {
+		return
+	}
+	y := err.(InfeasibleError).Farkas
+	rows := lp.original[1:]
+	if assert.Len(t, y, len(rows)) {
+		combination := make([]float64, lp.Width())
+		for i, row := range rows {
+			for j, a := range row {
+				combination[j] += y[i] * lp.rowInfo(i).sign * a
+			}
+		}
+		assert.Less(t, combination[0], -1e-9)
+		for _, v := range combination[1:] {
+			assert.GreaterOrEqual(t, v, -1e-9)
+		}
+	}
+}
+
+func TestLP_InfeasibleError(t *testing.T) {
+	// x <= 1 and x >= 2
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.AddConstraintLeq(2, 1)
+	lp.Optimize()
+	_, err := lp.Solution()
+	assertFarkas(t, lp, err)
+	y := err.(InfeasibleError).Farkas
+	assert.Greater(t, y[0], 0.0)
+	assert.Less(t, y[1], 0.0)
+	// callers checking for the NoSolutionError of earlier versions
+	assert.True(t, errors.Is(err, NoSolutionError{}))
+	var noSolution NoSolutionError
+	assert.True(t, errors.As(err, &noSolution))
+	assert.Equal(t, err.Error(), noSolution.Error())
+
+	// x + y = -1 has no nonnegative solution
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(4, 1)
+	lp.AddConstraintEq(-1, 1, 1)
+	assert.False(t, lp.Feasible())
+	lp.Optimize()
+	_, err = lp.Solution()
+	assertFarkas(t, lp, err)
+	assert.InDeltaSlice(t, []float64{0, 1}, err.(InfeasibleError).Farkas, EPSILON)
+
+	// LPs with bounds have no certificate
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintLeq(2, 1)
+	lp.SetBounds(0, 0, 1)
+	lp.Optimize()
+	_, err = lp.Solution()
+	assert.IsType(t, InfeasibleError{}, err)
+	assert.Nil(t, err.(InfeasibleError).Farkas)
+}
+
+func TestLP_InfeasibleErrorWarmStart(t *testing.T) {
+	// x1 + x2 >= 20 contradicts the constraints of sensitivityLP
+	lp := sensitivityLP()
+	lp.Optimize()
+	lp.AddConstraintLeq(20, 1, 1)
+	lp.Optimize()
+	assert.False(t, lp.Feasible())
+	_, err := lp.Solution()
+	assertFarkas(t, lp, err)
+}
+
+func TestLP_InfeasibleErrorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	infeasible := 0
+	for k := 0; k < 30; k++ {
+		lp := randomLP(r, 6, 6)
+		for i := 0; i < 2; i++ {
+			a := make([]float64, 6)
+			for j := range a {
+				a[j] = float64(r.Intn(5) + 1)
+			}
+			lp.AddConstraintLeq(float64(r.Intn(150)+50), a...)
+		}
+		if k%2 == 1 {
+			lp.SetEngine(REVISED)
+		}
+		lp.Optimize()
+		if lp.Feasible() {
+			continue
+		}
+		infeasible++
+		_, err := lp.Solution()
+		assertFarkas(t, lp, err)
+	}
+	assert.Greater(t, infeasible, 0)
+}
+
+// checks that Point + tRay is feasible for the constraints as added and the
+// bounds, and that the objective function increases along Ray
+func assertRay(t *testing.T, lp *LP, err error) {
+	if !assert.IsType(t, UnboundedError{}, err) {
+		return
+	}
+	e := err.(UnboundedError)
+	assert.GreaterOrEqual(t, e.Entering, 0)
+	assert.NotEqual(t, 0.0, e.Ray[e.Entering])
+	improvement := 0.0
+	for j, d := range e.Ray {
+		improvement += lp.original[0][j+1] * d
+	}
+	assert.Greater(t, improvement, 1e-9)
+	for _, step := range []float64{0, 1, 1000} {
+		x := make([]float64, len(e.Point))
+		for j := range x {
+			x[j] = e.Point[j] + step*e.Ray[j]
+			lower, upper := lp.Bounds(j)
+			assert.True(t, Fle(lower, x[j], 1e-9) && Fle(x[j], upper, 1e-9))
+		}
+		for _, row := range lp.original[1:] {
+			lhs := 0.0
+			for j, v := range x {
+				lhs += row[j+1] * v
+			}
+			assert.InDelta(t, row[0], lhs, 1e-6)
+		}
+	}
+}
+
+func TestLP_UnboundedError(t *testing.T) {
+	// max x + y st. x - y <= 2, x <= 3y
+	for _, engine := range []int{TABLEAU, REVISED} {
+		lp := NewLP()
+		lp.SetEngine(engine)
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+		lp.AddConstraintGeq(2, 1, -1)
+		lp.AddConstraintGeq(0, 1, -3)
+		lp.Optimize()
+		assert.False(t, lp.Bounded())
+		_, err := lp.Solution()
+		assertRay(t, lp, err)
+		ray := err.(UnboundedError).Ray
+		assert.Greater(t, ray[0], 0.0)
+		assert.Greater(t, ray[1], 0.0)
+		assert.True(t, errors.Is(err, NoSolutionError{}))
+		assert.False(t, errors.Is(err, SolutionUnavailableError{}))
+		var noSolution NoSolutionError
+		assert.True(t, errors.As(err, &noSolution))
+	}
+
+	// max x - y st. x + 2y <= 10 with y free
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, -1)
+	lp.AddConstraintGeq(10, 1, 2)
+	lp.SetBounds(1, math.Inf(-1), math.Inf(1))
+	lp.Optimize()
+	_, err := lp.Solution()
+	assertRay(t, lp, err)
+	assert.Less(t, err.(UnboundedError).Ray[1], 0.0)
+
+	// the LP relaxation of a MIP
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintLeq(1, 1)
+	lp.SetInteger(0)
+	_, err = lp.OptimizeMIP()
+	assert.IsType(t, UnboundedError{}, err)
+	assert.Equal(t, []float64{1, 1}, err.(UnboundedError).Ray)
+}
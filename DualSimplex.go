@@ -0,0 +1,146 @@
+package sago
+
+import "math"
+
+// re-optimizes an optimal LP to which inequality constraints have been added
+// since, starting from the previous optimal basis. The added constraints are
+// expressed in terms of the nonbasic variables and their slack variables made
+// basic, which keeps the objective function row nonnegative (dual feasible).
+// Returns false if the LP cannot be warm started.
+func (lp *LP) warmStart() bool {
+	// the dual simplex algorithm would lose the exactness of the EXACT engine
+	if !lp.warm || lp.engine == EXACT || lp.sparse != nil {
+		return false
+	}
+	lp.densify()
+	known := len(lp.basis)
+	for i := known; i < lp.NumConstraints(); i++ {
+		if lp.rowInfo(i).slack < 0 {
+			return false
+		}
+	}
+	for i := known; i < lp.NumConstraints(); i++ {
+		// the slack variable of a range is bounded
+		if info := lp.rowInfo(i); info.kind == constraintRange {
+			_, upper := lp.Bounds(info.slack)
+			if Flt(upper, 0, lp.GetTolerances().PrimalFeasibility) {
+				return false
+			}
+			if lp.bounded == nil {
+				lp.bounded = newBoundedColumns(0)
+			}
+			lp.bounded.extend(lp.Width() - 1)
+			lp.bounded.upper[info.slack] = upper
+		}
+	}
+	if lp.bounded != nil {
+		lp.bounded.extend(lp.Width() - 1)
+	}
+	for i := known; i < lp.NumConstraints(); i++ {
+		row := lp.tableau[i+1]
+		if lp.bounded != nil {
+			lp.bounded.transform(row)
+		}
+		for r, v := range lp.basis[:known] {
+			if v < 0 || row[v+1] == 0 {
+				continue
+			}
+			factor := row[v+1]
+			for j := range row {
+				row[j] -= factor * lp.tableau[r+1][j]
+			}
+		}
+		slack := lp.rowInfo(i).slack
+		if row[slack+1] < 0 {
+			ScalarVectorMultiply(-1, row)
+		}
+		lp.basis = append(lp.basis, slack)
+	}
+	lp.setPhase(DUALSIMPLEX)
+	lp.dualSimplex()
+	lp.setPhase(PHASEII)
+	if lp.feasible {
+		lp.simplex()
+	}
+	return true
+}
+
+func (lp *LP) dualSimplex() {
+	for !lp.feasibilityKnown && !lp.control.stopped() {
+		lp.dualSimplexIteration()
+	}
+}
+
+func (lp *LP) dualSimplexIteration() {
+	if lp.feasibilityKnown {
+		return
+	}
+
+	if lp.bounded != nil {
+		lp.complementAboveUpper()
+	}
+
+	// Choose the constraint with the most negative b to leave the basis
+	row := 0
+	tol := lp.GetTolerances()
+	for i, c := range lp.ListConstraints() {
+		if lp.bounded != nil && lp.basis[i] >= 0 && lp.bounded.free[lp.basis[i]] {
+			continue
+		}
+		if Flt(c[0], 0, tol.PrimalFeasibility) && (row == 0 || c[0] < lp.tableau[row][0]) {
+			row = i + 1
+		}
+	}
+
+	// primal feasible
+	if row == 0 {
+		lp.feasibilityKnown = true
+		lp.feasible = true
+		return
+	}
+
+	if !lp.control.next() {
+		return
+	}
+	if lp.bounded != nil {
+		lp.complementFreeColumns(row)
+	}
+	lp.trace.begin(lp.tableau)
+
+	// Ratio test
+	col := lp.dualRatioTest(row)
+
+	// infeasible: b < 0 but the constraint has no negative coefficient
+	if col == 0 {
+		lp.feasibilityKnown = true
+		lp.feasible = false
+		lp.unbounded = false
+		lp.optimal = false
+		lp.solved = true
+		return
+	}
+
+	leaving := lp.basis[row-1]
+	lp.pivot(row, col)
+	lp.trace.record(lp.tableau, row, col)
+	lp.reportIteration(col-1, leaving)
+}
+
+// returns the column with the lowest ratio of objective function coefficient
+// to the absolute value of a negative entry in the row
+func (lp *LP) dualRatioTest(row int) int {
+	col := 0
+	low := math.MaxFloat64
+	tol := lp.GetTolerances()
+	for j := 1; j < lp.Width(); j++ {
+		a := lp.tableau[row][j]
+		if Fge(a, 0, tol.Pivot) {
+			continue
+		}
+		ratio := lp.tableau[0][j] / -a
+		if Flt(ratio, low, tol.Zero) {
+			col, low = j, ratio
+		}
+	}
+	return col
+}
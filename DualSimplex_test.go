@@ -0,0 +1,105 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLP_DualSimplexIteration(t *testing.T) {
+	lp := NewLP()
+	lp.tableau = tableau{
+		{0, 0, 0, 2, 1},
+		{-2, 0, 1, -1, -1},
+		{3, 1, 0, 1, 0},
+	}
+	lp.basis = []int{1, 0}
+	lp.dualSimplexIteration()
+	expected := tableau{
+		{-2, 0, 1, 1, 0},
+		{2, 0, -1, 1, 1},
+		{3, 1, 0, 1, 0},
+	}
+	assert.Equal(t, expected, lp.tableau)
+	assert.Equal(t, []int{3, 0}, lp.basis)
+	assert.False(t, lp.feasibilityKnown)
+
+	lp.dualSimplexIteration()
+	assert.True(t, lp.feasibilityKnown)
+	assert.True(t, lp.feasible)
+
+	lp = NewLP()
+	lp.tableau = tableau{
+		{0, 0, 1},
+		{-2, 1, 1},
+	}
+	lp.basis = []int{0}
+	lp.dualSimplexIteration()
+	assert.True(t, lp.solved)
+	assert.False(t, lp.feasible)
+}
+
+func TestLP_OptimizeWarmStart(t *testing.T) {
+	lp := sensitivityLP()
+	lp.Optimize()
+	assert.Equal(t, float64(36), lp.ObjectiveValue())
+
+	// x1 + x2 <= 7
+	lp.AddConstraintGeq(7, 1, 1)
+	assert.False(t, lp.Solved())
+	assert.Len(t, lp.basis, 3)
+	lp.Optimize()
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+	sol, err := lp.Solution()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{1, 6, 3, 0, 3, 0}, sol, EPSILON)
+	assert.Len(t, lp.original, 5)
+
+	// optimizing again does not change the solution
+	lp.Optimize()
+	assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+
+	// the sensitivity report matches the report of the LP solved from scratch
+	cold := sensitivityLP()
+	cold.AddConstraintGeq(7, 1, 1)
+	cold.Optimize()
+	expected, _ := cold.Sensitivity()
+	actual, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, expected.ShadowPrices, actual.ShadowPrices, EPSILON)
+
+	// removing a constraint starts over from the LP as built
+	lp.RemoveConstraint(3)
+	lp.Optimize()
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+}
+
+func TestLP_OptimizeWarmStartInfeasible(t *testing.T) {
+	lp := sensitivityLP()
+	lp.Optimize()
+
+	// 10 <= x1 contradicts x1 <= 4
+	lp.AddConstraintLeq(10, 1, 0)
+	assert.False(t, lp.Feasible())
+	assert.True(t, lp.Solved())
+	_, err := lp.Solution()
+	assert.Error(t, err)
+
+	// relaxing the LP again needs a cold start
+	lp.RemoveConstraint(3)
+	assert.True(t, lp.Feasible())
+	lp.Optimize()
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+}
+
+func TestLP_OptimizeAfterAddingEq(t *testing.T) {
+	lp := sensitivityLP()
+	lp.Optimize()
+	lp.AddConstraintEq(1, 1, 0)
+	lp.Optimize()
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+	sol, _ := lp.Solution()
+	assert.InDelta(t, 1, sol[0], EPSILON)
+	assert.InDelta(t, 6, sol[1], EPSILON)
+}
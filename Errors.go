@@ -2,12 +2,17 @@ package sago
 
 import "fmt"
 
-//InvalidInputError input is invalid
+//InvalidInputError input is invalid. Line is the 1-based line number of the
+//input at which parsing failed, or 0 if the input was not parsed from text.
 type InvalidInputError struct {
-	s string
+	s    string
+	Line int
 }
 
 func (e InvalidInputError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", "Invalid input", e.Line, e.s)
+	}
 	return fmt.Sprintf("%s: %s", "Invalid input", e.s)
 }
 
@@ -20,6 +25,61 @@ func (e NoSolutionError) Error() string {
 	return fmt.Sprintf("%s: %s", "No solution", e.s)
 }
 
+//InfeasibleError the LP has no feasible solution. Farkas is a certificate of
+//infeasibility: a vector y with one entry per constraint, as added, such that
+//yᵀA >= 0 and yᵀb < 0 where b = Ax are the constraints including their slack
+//variables. Since Ax = b has no solution x >= 0, the constraints combined with
+//the nonzero entries of y contradict each other. Farkas is nil for LPs with
+//bounds and when Presolve finds the contradiction.
+type InfeasibleError struct {
+	s      string
+	Farkas []float64
+}
+
+func (e InfeasibleError) Error() string {
+	return fmt.Sprintf("%s: %s", "No solution", e.s)
+}
+
+//Is reports whether target is a NoSolutionError, which InfeasibleError used to be
+func (e InfeasibleError) Is(target error) bool {
+	_, ok := target.(NoSolutionError)
+	return ok
+}
+
+//Unwrap returns the NoSolutionError that InfeasibleError used to be
+func (e InfeasibleError) Unwrap() error {
+	return NoSolutionError{s: e.s}
+}
+
+//UnboundedError the objective function of the LP improves without limit.
+//Point is a feasible solution and Ray a direction, over the same variables,
+//such that Point + tRay is feasible for every t >= 0 and the objective function
+//improves as t grows; the variables with a nonzero entry in Ray change without
+//limit. Entering is the index of the variable whose column failed the ratio
+//test, or -1 if the ray is unknown. Point and Ray are nil when Presolve finds
+//the variable Entering that improves without limit.
+type UnboundedError struct {
+	s        string
+	Point    []float64
+	Ray      []float64
+	Entering int
+}
+
+func (e UnboundedError) Error() string {
+	return fmt.Sprintf("%s: %s", "No solution", e.s)
+}
+
+//Is reports whether target is a NoSolutionError, which UnboundedError used to be
+func (e UnboundedError) Is(target error) bool {
+	_, ok := target.(NoSolutionError)
+	return ok
+}
+
+//Unwrap returns the NoSolutionError that UnboundedError used to be
+func (e UnboundedError) Unwrap() error {
+	return NoSolutionError{s: e.s}
+}
+
 //SolutionUnavailableError the solution has not yet been calculated
 type SolutionUnavailableError struct {
 	s string
@@ -0,0 +1,595 @@
+package sago
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// tableau of the EXACT engine, laid out like the tableau of the LP with the
+// artificial variables of phase I in the last columns
+type exactSimplex struct {
+	lp        *LP
+	tableau   [][]*big.Rat
+	basis     []int        // basic variable of each constraint
+	n         int          // number of variables of the LP, or of the substituted LP
+	objective []*big.Rat   // objective function (z, c1, c2, ...) over the n variables
+	bounds    *exactBounds // substitution of the variables with bounds, or nil
+	phaseI    bool
+}
+
+// substitution of the variables of an LP with bounds by nonnegative variables
+// y: x_j = offset_j + direction_j y_j - y_negative_j, where negative_j is the
+// column of the negative part of a free variable, or -1. A variable bounded on
+// both sides gets the constraint y_j + s = width_j, its upper minus its lower
+// bound; width_j is nil otherwise.
+type exactBounds struct {
+	offset    []*big.Rat
+	direction []int
+	negative  []int
+	width     []*big.Rat
+}
+
+// executes the simplex algorithm on the LP with rational arithmetic. Both phases
+// use Bland's rule, which cannot cycle.
+func (lp *LP) exactSimplex() {
+	rows := make([][]*big.Rat, lp.NumConstraints())
+	for i := range rows {
+		rows[i] = exactRow(lp.original[i+1])
+	}
+	es := newExactSimplex(lp, rows, exactRow(lp.original[0]))
+	lp.exact = es
+	col := es.solve()
+	es.write()
+	if col > 0 {
+		lp.setRay(col)
+	}
+}
+
+// solves the LP with bounds with rational arithmetic after substituting
+// nonnegative variables for the variables with bounds, and writes its solution
+// to the tableau of the bounded-variable simplex at a basis found with
+// rational arithmetic too, so the status, the solution and the ray of the LP
+// are those of the EXACT engine. Returns false if the bounds contradict each
+// other, which the tableau engine reports.
+func (lp *LP) exactBoundedSimplex() bool {
+	eb, ok := newExactBounds(lp)
+	if !ok {
+		return false
+	}
+	rows, objective := eb.substitute(lp.original)
+	es := newExactSimplex(lp, rows, objective)
+	es.bounds = eb
+	lp.exact = es
+	col := es.solve()
+	if lp.control.stopped() || !lp.feasible {
+		return true
+	}
+	if !es.writeBounded(col) {
+		lp.solved, lp.optimal, lp.unbounded = false, false, false
+		lp.control.stop(NUMERICAL)
+	}
+	return true
+}
+
+// writes the solution of the substituted LP as the basic solution of the
+// tableau of the bounded-variable simplex, the variables at their upper bound
+// complemented, with the basic variables of the substituted LP basic as far as
+// they are basic in the LP; sets the ray if the substituted LP is unbounded
+// along the entering column col. Returns false, leaving the LP alone, if no
+// basis of the LP has that basic solution.
+func (es *exactSimplex) writeBounded(col int) bool {
+	lp := es.lp
+	eb := es.bounds
+	n := len(eb.offset)
+	m := lp.NumConstraints()
+
+	// x = offset + direction t with t >= 0, or t free
+	x := es.solution()
+	offset := make([]*big.Rat, n)
+	direction := append([]int{}, eb.direction...)
+	t := make([]*big.Rat, n)
+	atUpper := make([]bool, n)
+	for j := range x {
+		offset[j] = eb.offset[j]
+		t[j] = new(big.Rat).Sub(x[j], offset[j])
+		t[j].Mul(t[j], big.NewRat(int64(direction[j]), 1))
+		atUpper[j] = eb.width[j] != nil && t[j].Sign() > 0 && t[j].Cmp(eb.width[j]) == 0
+		if atUpper[j] {
+			offset[j] = new(big.Rat).Add(offset[j], new(big.Rat).Mul(eb.width[j], big.NewRat(int64(direction[j]), 1)))
+			t[j].SetInt64(0)
+			direction[j] = -direction[j]
+		} else if eb.negative[j] >= 0 && t[j].Sign() < 0 {
+			t[j].Neg(t[j])
+			direction[j] = -direction[j]
+		}
+	}
+
+	// the tableau of the LP in t, with the objective function in the form of
+	// negateObjectiveFunction
+	s := exactRat(lp.sense())
+	bs := &exactSimplex{lp: lp, n: n, tableau: make([][]*big.Rat, m+1), basis: make([]int, m)}
+	product := new(big.Rat)
+	for i, row := range lp.original {
+		bs.tableau[i] = make([]*big.Rat, n+1)
+		sum := new(big.Rat)
+		for j := 0; j < n; j++ {
+			a := exactRat(row[j+1])
+			sum.Add(sum, product.Mul(a, offset[j]))
+			bs.tableau[i][j+1] = a.Mul(a, big.NewRat(int64(direction[j]), 1))
+		}
+		if i == 0 {
+			bs.tableau[0][0] = sum.Add(sum, exactRat(row[0])).Mul(sum, s)
+			for j := 1; j <= n; j++ {
+				bs.tableau[0][j].Neg(bs.tableau[0][j]).Mul(bs.tableau[0][j], s)
+			}
+			continue
+		}
+		bs.tableau[i][0] = sum.Sub(exactRat(row[0]), sum)
+	}
+
+	// the variables strictly between their bounds must be basic, those basic in
+	// the substituted LP may be, then any variable fills the basis
+	basic := make([]bool, n)
+	for _, v := range es.basis {
+		switch {
+		case v < 0:
+		case v < n:
+			basic[v] = true
+		default:
+			for j, k := range eb.negative {
+				if k == v {
+					basic[j] = true
+				}
+			}
+		}
+	}
+	for r := range bs.basis {
+		bs.basis[r] = -1
+	}
+	inBasis := make([]bool, n)
+	enter := func(j int) bool {
+		for r := 1; r <= m; r++ {
+			if bs.basis[r-1] < 0 && bs.tableau[r][j+1].Sign() != 0 {
+				bs.pivot(r, j+1)
+				inBasis[j] = true
+				return true
+			}
+		}
+		return false
+	}
+	for j := range t {
+		if t[j].Sign() != 0 && !enter(j) {
+			return false
+		}
+	}
+	for j := range t {
+		if basic[j] && !atUpper[j] && !inBasis[j] {
+			enter(j)
+		}
+	}
+	for j := range t {
+		if !inBasis[j] {
+			enter(j)
+		}
+	}
+	for r, v := range bs.basis {
+		if v < 0 && bs.tableau[r+1][0].Sign() != 0 {
+			return false
+		}
+	}
+
+	if !lp.applyBounds() {
+		return false
+	}
+	for j := range offset {
+		lp.bounded.offset[j], _ = offset[j].Float64()
+		lp.bounded.direction[j] = float64(direction[j])
+	}
+	bs.write()
+	if col > 0 {
+		// the substituted variables change by d along the ray, and t by
+		// direction dx
+		d := make([]*big.Rat, es.n)
+		for k := range d {
+			d[k] = new(big.Rat)
+		}
+		d[col-1].SetInt64(1)
+		for r, v := range es.basis {
+			if v >= 0 {
+				d[v].Neg(es.tableau[r+1][col])
+			}
+		}
+		lp.ray = make([]float64, lp.Width()-1)
+		for j, dx := range eb.solution(d) {
+			dx.Sub(dx, eb.offset[j])
+			lp.ray[j], _ = dx.Mul(dx, big.NewRat(int64(direction[j]), 1)).Float64()
+		}
+	}
+	return true
+}
+
+func newExactSimplex(lp *LP, rows [][]*big.Rat, objective []*big.Rat) *exactSimplex {
+	n := len(objective) - 1
+	m := len(rows)
+	es := &exactSimplex{lp: lp, n: n, objective: objective, basis: make([]int, m)}
+	es.tableau = make([][]*big.Rat, m+1)
+	for i := range es.tableau {
+		es.tableau[i] = make([]*big.Rat, n+m+1)
+		for j := range es.tableau[i] {
+			es.tableau[i][j] = new(big.Rat)
+		}
+	}
+	for r := 1; r <= m; r++ {
+		for j, v := range rows[r-1] {
+			es.tableau[r][j].Set(v)
+		}
+		es.tableau[r][n+r].SetInt64(1)
+	}
+	return es
+}
+
+// runs both phases on the tableau, whose constraints have b >= 0, and sets the
+// status of the LP; returns the entering column if the LP is unbounded and 0
+// otherwise
+func (es *exactSimplex) solve() int {
+	lp := es.lp
+
+	// Phase I: maximize -(sum of artificial variables)
+	es.phaseI = true
+	lp.setPhase(PHASEI)
+	m := len(es.basis)
+	for r := range es.basis {
+		es.basis[r] = es.n + r
+		es.tableau[0][es.n+r+1].SetInt64(1)
+	}
+	es.priceOut()
+	es.simplex()
+	if lp.control.stopped() {
+		return 0
+	}
+	lp.feasibilityKnown = true
+	lp.feasible = es.tableau[0][0].Sign() == 0
+	if !lp.feasible {
+		lp.unbounded = false
+		lp.optimal = false
+		lp.solved = true
+		return 0
+	}
+
+	// Phase II: drop the artificial variables and restore the objective function
+	es.phaseI = false
+	lp.setPhase(PHASEII)
+	es.driveOutArtificials()
+	for i, row := range es.tableau {
+		es.tableau[i] = row[:es.n+1]
+	}
+	s := exactRat(lp.sense())
+	es.tableau[0][0] = new(big.Rat).Mul(es.objective[0], s)
+	for j := 1; j <= es.n; j++ {
+		es.tableau[0][j] = new(big.Rat).Neg(es.objective[j])
+		es.tableau[0][j].Mul(es.tableau[0][j], s)
+	}
+	for r := 0; r < m; r++ {
+		if es.basis[r] >= es.n {
+			es.basis[r] = -1
+		}
+	}
+	es.priceOut()
+	col := es.simplex()
+	if lp.control.stopped() {
+		return 0
+	}
+	lp.unbounded = col > 0
+	lp.optimal = !lp.unbounded
+	lp.solved = true
+	return col
+}
+
+// the substitution for the bounds of the LP; false if they contradict each other
+func newExactBounds(lp *LP) (*exactBounds, bool) {
+	n := lp.Width() - 1
+	eb := &exactBounds{
+		offset:    make([]*big.Rat, n),
+		direction: make([]int, n),
+		negative:  make([]int, n),
+		width:     make([]*big.Rat, n),
+	}
+	columns := n
+	for j := 0; j < n; j++ {
+		lower, upper := lp.Bounds(j)
+		eb.offset[j], eb.direction[j], eb.negative[j] = new(big.Rat), 1, -1
+		switch {
+		case lower > upper:
+			return nil, false
+		case !math.IsInf(lower, -1):
+			eb.offset[j].Set(exactRat(lower))
+			if !math.IsInf(upper, 1) {
+				eb.width[j] = new(big.Rat).Sub(exactRat(upper), eb.offset[j])
+			}
+		case !math.IsInf(upper, 1):
+			eb.offset[j], eb.direction[j] = exactRat(upper), -1
+		default:
+			eb.negative[j] = columns
+			columns++
+		}
+	}
+	return eb, true
+}
+
+// the constraints with b >= 0 and the objective function of the substituted LP,
+// from the tableau t of the LP as built
+func (eb *exactBounds) substitute(t tableau) ([][]*big.Rat, []*big.Rat) {
+	n := len(eb.offset)
+	columns := n
+	for j := range eb.offset {
+		if eb.negative[j] >= 0 {
+			columns++
+		}
+	}
+	slack := columns
+	for _, w := range eb.width {
+		if w != nil {
+			columns++
+		}
+	}
+	zeros := func() []*big.Rat {
+		row := make([]*big.Rat, columns+1)
+		for j := range row {
+			row[j] = new(big.Rat)
+		}
+		return row
+	}
+
+	// a(offset + direction y - y') = a offset + (direction a) y - a y'; the
+	// first entry of the result is a offset
+	substitute := func(row []float64) []*big.Rat {
+		sub := zeros()
+		product := new(big.Rat)
+		for j := 0; j < n && j+1 < len(row); j++ {
+			if row[j+1] == 0 {
+				continue
+			}
+			a := exactRat(row[j+1])
+			sub[0].Add(sub[0], product.Mul(a, eb.offset[j]))
+			sub[j+1].Mul(a, big.NewRat(int64(eb.direction[j]), 1))
+			if k := eb.negative[j]; k >= 0 {
+				sub[k+1].Neg(a)
+			}
+		}
+		return sub
+	}
+
+	objective := substitute(t[0])
+	objective[0].Add(objective[0], exactRat(t[0][0]))
+	var rows [][]*big.Rat
+	for _, row := range t[1:] {
+		sub := substitute(row)
+		sub[0].Sub(exactRat(row[0]), sub[0])
+		if sub[0].Sign() < 0 {
+			for _, v := range sub {
+				v.Neg(v)
+			}
+		}
+		rows = append(rows, sub)
+	}
+	for j, w := range eb.width {
+		if w == nil {
+			continue
+		}
+		row := zeros()
+		row[0].Set(w)
+		row[j+1].SetInt64(1)
+		row[slack+1].SetInt64(1)
+		slack++
+		rows = append(rows, row)
+	}
+	return rows, objective
+}
+
+// the values x of the variables of the LP for the values y of the substituted LP
+func (eb *exactBounds) solution(y []*big.Rat) []*big.Rat {
+	x := make([]*big.Rat, len(eb.offset))
+	for j := range x {
+		x[j] = new(big.Rat).Mul(y[j], big.NewRat(int64(eb.direction[j]), 1))
+		x[j].Add(x[j], eb.offset[j])
+		if k := eb.negative[j]; k >= 0 {
+			x[j].Sub(x[j], y[k])
+		}
+	}
+	return x
+}
+
+// the basic solution of the tableau, in the variables of the LP
+func (es *exactSimplex) solution() []*big.Rat {
+	sol := make([]*big.Rat, es.n)
+	for j := range sol {
+		sol[j] = new(big.Rat)
+	}
+	for r, v := range es.basis {
+		if v >= 0 && v < es.n {
+			sol[v].Set(es.tableau[r+1][0])
+		}
+	}
+	if es.bounds != nil {
+		return es.bounds.solution(sol)
+	}
+	return sol
+}
+
+// runs simplex iterations until the current phase is optimal or unbounded;
+// returns the entering column if it is unbounded and 0 otherwise
+func (es *exactSimplex) simplex() int {
+	for {
+		col := 0
+		for j := 1; j < len(es.tableau[0]) && col == 0; j++ {
+			if es.tableau[0][j].Sign() < 0 {
+				col = j
+			}
+		}
+		if col == 0 || !es.lp.control.next() {
+			return 0
+		}
+		row := es.ratioTest(col)
+		if row == 0 {
+			return col
+		}
+		leaving := es.basis[row-1]
+		es.pivot(row, col)
+		es.report(col-1, leaving)
+	}
+}
+
+// returns the constraint with the lowest ratio, breaking ties by the lowest
+// index of the basic variable, or 0 if there is no positive entry in col
+func (es *exactSimplex) ratioTest(col int) int {
+	row := 0
+	low := new(big.Rat)
+	ratio := new(big.Rat)
+	for r := 1; r < len(es.tableau); r++ {
+		a := es.tableau[r][col]
+		if a.Sign() <= 0 {
+			continue
+		}
+		ratio.Quo(es.tableau[r][0], a)
+		if row == 0 {
+			row = r
+			low.Set(ratio)
+			continue
+		}
+		switch ratio.Cmp(low) {
+		case -1:
+			row = r
+			low.Set(ratio)
+		case 0:
+			if es.basis[r-1] < es.basis[row-1] {
+				row = r
+			}
+		}
+	}
+	return row
+}
+
+func (es *exactSimplex) pivot(row, col int) {
+	inverse := new(big.Rat).Inv(es.tableau[row][col])
+	for _, v := range es.tableau[row] {
+		v.Mul(v, inverse)
+	}
+	product := new(big.Rat)
+	for i, r := range es.tableau {
+		factor := new(big.Rat).Set(r[col])
+		if i == row || factor.Sign() == 0 {
+			continue
+		}
+		for j, v := range r {
+			v.Sub(v, product.Mul(factor, es.tableau[row][j]))
+		}
+	}
+	es.basis[row-1] = col - 1
+}
+
+// makes the objective function coefficients of basic variables 0
+func (es *exactSimplex) priceOut() {
+	product := new(big.Rat)
+	for r, v := range es.basis {
+		if v < 0 {
+			continue
+		}
+		factor := new(big.Rat).Set(es.tableau[0][v+1])
+		if factor.Sign() == 0 {
+			continue
+		}
+		for j, a := range es.tableau[0] {
+			a.Sub(a, product.Mul(factor, es.tableau[r+1][j]))
+		}
+	}
+}
+
+// pivots basic artificial variables out of the basis where the constraint has a
+// nonzero variable of the LP
+func (es *exactSimplex) driveOutArtificials() {
+	for r, v := range es.basis {
+		if v < es.n {
+			continue
+		}
+		for j := 1; j <= es.n; j++ {
+			if es.tableau[r+1][j].Sign() != 0 {
+				es.pivot(r+1, j)
+				break
+			}
+		}
+	}
+}
+
+// copies the tableau and the basis to the LP, rounded to float64
+func (es *exactSimplex) write() {
+	lp := es.lp
+	for i, row := range es.tableau {
+		for j := range lp.tableau[i] {
+			lp.tableau[i][j], _ = row[j].Float64()
+		}
+	}
+	lp.basis = make([]int, len(es.basis))
+	for r, v := range es.basis {
+		lp.basis[r] = v
+		if v >= es.n {
+			lp.basis[r] = -1
+		}
+	}
+}
+
+// reports a pivot of the EXACT engine
+func (es *exactSimplex) report(entering, leaving int) {
+	c := es.lp.control
+	if !c.reporting() {
+		return
+	}
+	objective, _ := es.tableau[0][0].Float64()
+	p := Progress{Entering: entering, Leaving: leaving, ObjectiveValue: objective}
+	if es.phaseI {
+		p.Infeasibility = math.Max(0, -objective)
+	} else {
+		p.ObjectiveValue *= es.lp.sense()
+	}
+	c.report(p)
+}
+
+//ExactSolution returns the solution of an LP optimized with the EXACT engine as
+//fractions
+func (lp *LP) ExactSolution() ([]*big.Rat, error) {
+	if _, err := lp.Solution(); err != nil {
+		return nil, err
+	}
+	if lp.exact == nil {
+		return nil, SolutionUnavailableError{"LP was not optimized with the EXACT engine"}
+	}
+	return lp.exact.solution(), nil
+}
+
+//ExactObjectiveValue returns the objective value of an LP optimized with the
+//EXACT engine as a fraction
+func (lp *LP) ExactObjectiveValue() (*big.Rat, error) {
+	if _, err := lp.Solution(); err != nil {
+		return nil, err
+	}
+	if lp.exact == nil {
+		return nil, SolutionUnavailableError{"LP was not optimized with the EXACT engine"}
+	}
+	return new(big.Rat).Mul(lp.exact.tableau[0][0], exactRat(lp.sense())), nil
+}
+
+// the fraction of the shortest decimal that rounds to v, so that 0.1 is 1/10
+// rather than the binary fraction nearest to it
+func exactRat(v float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	return r
+}
+
+// the fractions of the entries of a row of the tableau
+func exactRow(row []float64) []*big.Rat {
+	r := make([]*big.Rat, len(row))
+	for j, v := range row {
+		r[j] = exactRat(v)
+	}
+	return r
+}
@@ -0,0 +1,246 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestLP_ExactSolution(t *testing.T) {
+	lp := sensitivityLP()
+	lp.SetEngine(EXACT)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	sol, err := lp.ExactSolution()
+	assert.NoError(t, err)
+	expected := []*big.Rat{big.NewRat(2, 1), big.NewRat(6, 1), big.NewRat(2, 1), big.NewRat(0, 1), big.NewRat(0, 1)}
+	assert.Equal(t, ratStrings(expected), ratStrings(sol))
+	objective, err := lp.ExactObjectiveValue()
+	assert.NoError(t, err)
+	assert.Equal(t, "36/1", objective.String())
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+
+	// coefficients are read as decimals
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 0.1, 1)
+	lp.AddConstraintGeq(0.3, 0.1, 0.2)
+	lp.AddConstraintGeq(0.7, 0.3, 0.1)
+	lp.SetEngine(EXACT)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	sol, _ = lp.ExactSolution()
+	assert.Equal(t, []string{"0/1", "3/2", "0/1", "11/20"}, ratStrings(sol))
+	objective, _ = lp.ExactObjectiveValue()
+	assert.Equal(t, "3/2", objective.String())
+	assert.InDeltaSlice(t, []float64{0, 1.5, 0, .55}, result.Solution, EPSILON)
+
+	// Bland's rule does not cycle
+	lp = bealeLP()
+	lp.SetEngine(EXACT)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	objective, _ = lp.ExactObjectiveValue()
+	assert.Equal(t, "5/4", objective.String())
+	sol, _ = lp.ExactSolution()
+	assert.Equal(t, []string{"3/4", "0/1", "0/1", "1/1", "0/1", "1/1", "0/1"}, ratStrings(sol))
+
+	// adding a constraint solves the LP again exactly
+	lp.AddConstraintGeq(0.5, 0, 0, 0, 1)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	objective, _ = lp.ExactObjectiveValue()
+	assert.Equal(t, 1, objective.Cmp(big.NewRat(0, 1)))
+	assert.Equal(t, -1, objective.Cmp(big.NewRat(5, 4)))
+}
+
+func TestLP_ExactSolution_Errors(t *testing.T) {
+	lp := sensitivityLP()
+	lp.Optimize()
+	_, err := lp.ExactSolution()
+	assert.IsType(t, SolutionUnavailableError{}, err)
+	_, err = lp.ExactObjectiveValue()
+	assert.IsType(t, SolutionUnavailableError{}, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, 1, 1)
+	lp.AddConstraintLeq(2, 1, 1)
+	lp.SetEngine(EXACT)
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+	_, err = lp.ExactSolution()
+	assert.IsType(t, InfeasibleError{}, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, 1, -1)
+	lp.SetEngine(EXACT)
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+	_, err = lp.ExactObjectiveValue()
+	if assert.IsType(t, UnboundedError{}, err) {
+		assert.InDeltaSlice(t, []float64{1, 1, 0}, err.(UnboundedError).Ray, EPSILON)
+	}
+}
+
+func TestLP_ExactSolution_Random(t *testing.T) {
+	random := rand.New(rand.NewSource(21))
+	for test := 0; test < 50; test++ {
+		n, m := 2+random.Intn(4), 1+random.Intn(4)
+		lp := NewLP()
+		objective := make([]float64, n)
+		for j := range objective {
+			objective[j] = float64(random.Intn(10))
+		}
+		lp.SetObjectiveFunction(MAXIMIZE, 0, objective...)
+		for i := 0; i < m; i++ {
+			coefs := make([]float64, n)
+			for j := range coefs {
+				coefs[j] = float64(random.Intn(7))
+			}
+			lp.AddConstraintGeq(float64(1+random.Intn(20)), coefs...)
+		}
+		exact := lp.Copy()
+		lp.Optimize()
+		exact.SetEngine(EXACT)
+		result := exact.Optimize()
+		if !lp.Optimal() {
+			assert.Equal(t, UNBOUNDED, result.Status)
+			continue
+		}
+		assert.Equal(t, OPTIMAL, result.Status)
+		assert.InDelta(t, lp.ObjectiveValue(), result.ObjectiveValue, 1e-9)
+		value, _ := exact.ExactObjectiveValue()
+		f, _ := value.Float64()
+		assert.Equal(t, result.ObjectiveValue, f)
+	}
+}
+
+func TestLP_ExactSolution_Bounds(t *testing.T) {
+	// max x1 + 2x2 - x3 st. x1 + x2 + x3 <= 10, x2 - x3 <= 4, 0.1 <= x1 <= 0.3,
+	// x2 <= 2.5 and x3 free
+	bounded := func(objective int, sign float64) *LP {
+		lp := NewLP()
+		lp.SetObjectiveFunction(objective, 0, sign, 2*sign, -sign)
+		lp.AddConstraintGeq(10, 1, 1, 1)
+		lp.AddConstraintGeq(4, 0, 1, -1)
+		lp.SetBounds(0, 0.1, 0.3)
+		lp.SetBounds(1, math.Inf(-1), 2.5)
+		lp.SetBounds(2, math.Inf(-1), math.Inf(1))
+		lp.SetEngine(EXACT)
+		return lp
+	}
+	for _, test := range []struct {
+		objective int
+		sign      float64
+		value     string
+	}{{MAXIMIZE, 1, "34/5"}, {MINIMIZE, -1, "-34/5"}} {
+		lp := bounded(test.objective, test.sign)
+		result := lp.Optimize()
+		assert.Equal(t, OPTIMAL, result.Status)
+		sol, err := lp.ExactSolution()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"3/10", "5/2", "-3/2", "87/10", "0/1"}, ratStrings(sol))
+		objective, err := lp.ExactObjectiveValue()
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, objective.String())
+		assert.InDelta(t, 6.8*test.sign, result.ObjectiveValue, EPSILON)
+		assert.InDeltaSlice(t, []float64{.3, 2.5, -1.5, 8.7, 0}, result.Solution, EPSILON)
+		assert.Equal(t, 6.8*test.sign, result.ObjectiveValue)
+		// the basis of the EXACT engine, without pivots of the tableau engine
+		assert.ElementsMatch(t, []int{2, 3}, lp.Basis())
+	}
+
+	lp := bounded(MAXIMIZE, 1)
+	lp.SetBounds(0, 11, 12)
+	lp.SetBounds(1, 0, 1)
+	lp.SetBounds(2, 0, 1)
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+	_, err := lp.ExactSolution()
+	assert.IsType(t, InfeasibleError{}, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, -1)
+	lp.AddConstraintGeq(1, 1)
+	lp.SetBounds(1, math.Inf(-1), math.Inf(1))
+	lp.SetEngine(EXACT)
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+	_, err = lp.ExactObjectiveValue()
+	if assert.IsType(t, UnboundedError{}, err) {
+		assert.InDeltaSlice(t, []float64{0, -1, 0}, err.(UnboundedError).Ray, EPSILON)
+	}
+}
+
+func TestLP_ExactSolution_RandomBounds(t *testing.T) {
+	random := rand.New(rand.NewSource(22))
+	for test := 0; test < 50; test++ {
+		n, m := 2+random.Intn(4), 1+random.Intn(4)
+		lp := NewLP()
+		objective := make([]float64, n)
+		for j := range objective {
+			objective[j] = float64(random.Intn(10) - 3)
+		}
+		objectiveType := MAXIMIZE
+		if test%2 == 1 {
+			objectiveType = MINIMIZE
+		}
+		lp.SetObjectiveFunction(objectiveType, 0, objective...)
+		for i := 0; i < m; i++ {
+			coefs := make([]float64, n)
+			for j := range coefs {
+				coefs[j] = float64(random.Intn(7) - 2)
+			}
+			lp.AddConstraintGeq(float64(1+random.Intn(20)), coefs...)
+		}
+		for j := 0; j < n; j++ {
+			lower, upper := float64(random.Intn(5)-2), float64(random.Intn(5)+3)
+			switch random.Intn(4) {
+			case 0:
+				lower = math.Inf(-1)
+			case 1:
+				upper = math.Inf(1)
+			case 2:
+				lower, upper = math.Inf(-1), math.Inf(1)
+			}
+			lp.SetBounds(j, lower, upper)
+		}
+		exact := lp.Copy()
+		expected := lp.Optimize()
+		exact.SetEngine(EXACT)
+		result := exact.Optimize()
+		assert.Equal(t, expected.Status, result.Status, test)
+		if result.Status == UNBOUNDED {
+			// the objective function improves along the ray of the EXACT engine
+			_, err := exact.Solution()
+			ray := err.(UnboundedError).Ray
+			improvement := 0.0
+			for j, c := range objective {
+				improvement += c * ray[j]
+			}
+			if objectiveType == MINIMIZE {
+				improvement = -improvement
+			}
+			assert.True(t, improvement > 0, test)
+		}
+		if expected.Status != OPTIMAL {
+			continue
+		}
+		assert.InDelta(t, expected.ObjectiveValue, result.ObjectiveValue, 1e-9, test)
+		value, err := exact.ExactObjectiveValue()
+		assert.NoError(t, err, test)
+		f, _ := value.Float64()
+		assert.Equal(t, f, result.ObjectiveValue, test)
+		sol, _ := exact.ExactSolution()
+		for j := range sol {
+			x, _ := sol[j].Float64()
+			assert.InDelta(t, x, result.Solution[j], 1e-12, test)
+			lower, upper := exact.Bounds(j)
+			assert.True(t, x >= lower && x <= upper, test)
+		}
+	}
+}
+
+func ratStrings(rats []*big.Rat) []string {
+	s := make([]string, len(rats))
+	for i, r := range rats {
+		s[i] = r.String()
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package sago
+
+import "fmt"
+
+//FeasRelaxResult describes the closest plan found by FeasRelax
+type FeasRelaxResult struct {
+	Violation      float64   // minimal weighted sum of the violations
+	Relaxation     []float64 // change to b that makes each constraint, as added, hold
+	Relaxed        []int     // indices of the constraints with a nonzero relaxation
+	Solution       []float64 // values of the variables of the LP
+	ObjectiveValue float64
+}
+
+//FeasRelax finds the plan closest to satisfying the constraints of an LP that
+//may be infeasible. Like the auxiliary LP of phase I, it adds elastic variables
+//to every constraint, here one in each direction: b = a1x1 + a2x2 + ... + p - n.
+//The weighted violation w(p + n) is minimized first, then the objective
+//function of the LP among the plans with minimal violation. weights holds the
+//weight of each constraint, by index; missing weights are 1. Bounds of the
+//variables are not relaxed. Returns the error of Solution if the relaxed LP has
+//no solution.
+func (lp *LP) FeasRelax(weights ...float64) (*FeasRelaxResult, error) {
+	m := lp.NumConstraints()
+	if len(weights) > m {
+		return nil, InvalidInputError{s: fmt.Sprintf("%d weights for %d constraints", len(weights), m)}
+	}
+	w := make([]float64, m)
+	for i := range w {
+		w[i] = 1
+		if i < len(weights) {
+			w[i] = weights[i]
+		}
+		if w[i] < 0 {
+			return nil, InvalidInputError{s: fmt.Sprintf("negative weight %v of constraint %d", w[i], i)}
+		}
+	}
+
+	r, elastic := lp.elasticLP()
+	objective := r.tableau[0]
+
+	// minimize the violation
+	violation := make([]float64, r.Width()-1)
+	for i, weight := range w {
+		violation[elastic+2*i] = weight
+		violation[elastic+2*i+1] = weight
+	}
+	r.SetObjectiveFunction(MAXIMIZE, 0, ScalarVectorMultiply(-1, append([]float64{}, violation...))...)
+	r.solve()
+	if _, err := r.Solution(); err != nil {
+		return nil, err
+	}
+	result := &FeasRelaxResult{Violation: -r.ObjectiveValue()}
+
+	// optimize the objective function with at most the minimal violation
+	r.AddConstraintGeq(result.Violation, violation...)
+	r.SetObjectiveFunction(lp.objective, objective[0], extend(objective[1:], r.Width()-1)...)
+	r.solve()
+	sol, err := r.Solution()
+	if err != nil {
+		return nil, err
+	}
+	result.Solution = sol[:lp.Width()-1]
+	result.ObjectiveValue = r.ObjectiveValue()
+	result.Relaxation = make([]float64, m)
+	for i := range result.Relaxation {
+		p, n := sol[elastic+2*i], sol[elastic+2*i+1]
+		result.Relaxation[i] = lp.rowInfo(i).sign * (n - p)
+		if !Feq(result.Relaxation[i], 0, lp.GetTolerances().PrimalFeasibility) {
+			result.Relaxed = append(result.Relaxed, i)
+		}
+	}
+	return result, nil
+}
+
+// copies the LP as built with the elastic variables p_i and n_i of the i-th
+// constraint in the columns elastic + 2i and elastic + 2i + 1
+func (lp *LP) elasticLP() (*LP, int) {
+	r := lp.Copy()
+	r.reset()
+	r.densify()
+	elastic := r.Width() - 1
+	m := r.NumConstraints()
+	r.increaseWidth(elastic + 1 + 2*m)
+	for i := 0; i < m; i++ {
+		r.tableau[i+1][elastic+2*i+1] = 1
+		r.tableau[i+1][elastic+2*i+2] = -1
+	}
+	return r, elastic
+}
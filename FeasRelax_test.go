@@ -0,0 +1,92 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLP_FeasRelax(t *testing.T) {
+	// max x st. x <= 1, x >= 3
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.AddConstraintLeq(3, 1)
+	result, err := lp.FeasRelax()
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, result.Violation, EPSILON)
+	assert.InDeltaSlice(t, []float64{3, 0, 0}, result.Solution, 1e-9)
+	assert.InDelta(t, 3, result.ObjectiveValue, 1e-9)
+	assert.InDeltaSlice(t, []float64{2, 0}, result.Relaxation, 1e-9)
+	assert.Equal(t, []int{0}, result.Relaxed)
+
+	// relaxing x >= 3 is cheaper
+	result, err = lp.FeasRelax(2)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, result.Violation, 1e-9)
+	assert.InDeltaSlice(t, []float64{0, -2}, result.Relaxation, 1e-9)
+	assert.Equal(t, []int{1}, result.Relaxed)
+	assert.InDelta(t, 1, result.Solution[0], 1e-9)
+
+	// -x - y = 2 relaxed by 2 in the constraint as added
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, -1, -1)
+	lp.AddConstraintEq(2, -1, -1)
+	result, err = lp.FeasRelax()
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, result.Violation, 1e-9)
+	assert.InDeltaSlice(t, []float64{-2}, result.Relaxation, 1e-9)
+
+	_, err = lp.FeasRelax(1, 1)
+	assert.IsType(t, InvalidInputError{}, err)
+	_, err = lp.FeasRelax(-1)
+	assert.IsType(t, InvalidInputError{}, err)
+}
+
+func TestLP_FeasRelaxFeasible(t *testing.T) {
+	lp := sensitivityLP()
+	result, err := lp.FeasRelax()
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, result.Violation, EPSILON)
+	assert.Empty(t, result.Relaxed)
+	assert.InDelta(t, 36, result.ObjectiveValue, 1e-9)
+	assert.InDeltaSlice(t, []float64{2, 6}, result.Solution[:2], 1e-9)
+
+	// the LP itself is left alone
+	lp.Optimize()
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+	assert.Equal(t, 3, lp.NumConstraints())
+}
+
+func TestLP_FeasRelaxRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	for k := 0; k < 20; k++ {
+		lp := randomLP(r, 6, 5)
+		for i := 0; i < 3; i++ {
+			a := make([]float64, 5)
+			for j := range a {
+				a[j] = float64(r.Intn(5) + 1)
+			}
+			lp.AddConstraintLeq(float64(r.Intn(150)+50), a...)
+		}
+		feasible := lp.Feasible()
+		result, err := lp.FeasRelax()
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, feasible, Feq(result.Violation, 0, 1e-9))
+		total := 0.0
+		for i, row := range lp.original[1:] {
+			total += math.Abs(result.Relaxation[i])
+			// the constraint as added holds with b + relaxation
+			lhs := 0.0
+			for j, x := range result.Solution {
+				lhs += row[j+1] * x
+			}
+			sign := lp.rowInfo(i).sign
+			assert.InDelta(t, sign*row[0]+result.Relaxation[i], sign*lhs, 1e-6)
+		}
+		assert.InDelta(t, result.Violation, total, 1e-6)
+	}
+}
@@ -0,0 +1,137 @@
+package sago
+
+import "math"
+
+// rounds of cuts added by OptimizeGomory(0); cuts from the tableau alone can
+// take arbitrarily many rounds to reach an integral solution
+const gomoryRounds = 50
+
+//GomoryCuts derives a Gomory mixed-integer cut from each row of the optimal
+//tableau whose basic variable is marked as integer and has a fractional value.
+//A cut (b, a1, a2, ...) is the constraint b <= a1x1 + a2x2 + ... as added by
+//AddConstraintLeq; every cut is violated by the current solution. The slack
+//variable of a constraint with integer coefficients on integer variables only
+//is integer as well; every other variable that is not marked as integer is
+//treated as continuous.
+func (lp *LP) GomoryCuts() ([][]float64, error) {
+	if _, err := lp.Solution(); err != nil {
+		return nil, err
+	}
+	lp.densify()
+	basis := lp.basis
+	if basis == nil {
+		basis = lp.findBasis()
+	}
+	integer := lp.integerColumns()
+	basic := make([]bool, lp.Width()-1)
+	for _, v := range basis {
+		if v >= 0 {
+			basic[v] = true
+		}
+	}
+
+	tol := lp.GetTolerances()
+	var cuts [][]float64
+	for r, v := range basis {
+		if v < 0 || !integer[v] {
+			continue
+		}
+		row := lp.tableau[r+1]
+		f0 := row[0] - math.Floor(row[0])
+		if f0 < integralityTolerance || f0 > 1-integralityTolerance {
+			continue
+		}
+
+		// x_v + sum a_j x_j = b over the nonbasic variables x_j
+		cut := make([]float64, len(row))
+		cut[0] = 1
+		for j := 1; j < len(row); j++ {
+			a := row[j]
+			if basic[j-1] || Feq(a, 0, tol.Zero) {
+				continue
+			}
+			if integer[j-1] {
+				f := a - math.Floor(a)
+				if f <= f0 {
+					cut[j] = f / f0
+				} else {
+					cut[j] = (1 - f) / (1 - f0)
+				}
+			} else if a > 0 {
+				cut[j] = a / f0
+			} else {
+				cut[j] = -a / (1 - f0)
+			}
+		}
+		if lp.bounded != nil {
+			// t_j = direction_j * (x_j - offset_j)
+			for j := 1; j < len(cut); j++ {
+				cut[j] *= lp.bounded.direction[j-1]
+				cut[0] += cut[j] * lp.bounded.offset[j-1]
+			}
+		}
+		cuts = append(cuts, cut)
+	}
+	return cuts, nil
+}
+
+//OptimizeGomory optimizes the LP, then adds the Gomory cuts of the optimal
+//tableau and re-optimizes until the solution is integral in the integer
+//variables, no cut can be derived, or maxRounds rounds of cuts have been added
+//(50 for 0). Returns the number of cuts added to the LP.
+func (lp *LP) OptimizeGomory(maxRounds int) (int, error) {
+	if maxRounds <= 0 {
+		maxRounds = gomoryRounds
+	}
+	lp.solve()
+	added := 0
+	for round := 0; round < maxRounds; round++ {
+		cuts, err := lp.GomoryCuts()
+		if err != nil {
+			return added, err
+		}
+		if len(cuts) == 0 {
+			break
+		}
+		for _, cut := range cuts {
+			lp.AddConstraintLeq(cut[0], cut[1:]...)
+		}
+		added += len(cuts)
+		lp.solve()
+	}
+	_, err := lp.Solution()
+	return added, err
+}
+
+// marks the integer variables and the slack variables of constraints whose
+// coefficients and right hand side are integers and that have nonzero
+// coefficients on integer variables only
+func (lp *LP) integerColumns() []bool {
+	rows := lp.tableau
+	if lp.dirty {
+		rows = lp.original
+	}
+	integer := make([]bool, lp.Width()-1)
+	for c := range integer {
+		// the tableau variable of an integer variable with a fractional bound is not integer
+		integer[c] = lp.integers[c] && (lp.bounded == nil || lp.bounded.offset[c] == math.Round(lp.bounded.offset[c]))
+	}
+	for i, row := range rows[1:] {
+		slack := lp.rowInfo(i).slack
+		if slack < 0 {
+			continue
+		}
+		integral := true
+		for j, a := range row {
+			if j == slack+1 || a == 0 {
+				continue
+			}
+			if a != math.Round(a) || j > 0 && !lp.integers[j-1] {
+				integral = false
+				break
+			}
+		}
+		integer[slack] = integral
+	}
+	return integer
+}
@@ -0,0 +1,80 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLP_GomoryCuts(t *testing.T) {
+	lp := integerLP()
+	_, err := lp.GomoryCuts()
+	assert.Error(t, err)
+
+	lp.Optimize()
+	sol, _ := lp.Solution()
+	assert.InDeltaSlice(t, []float64{2.4, 2.4, 1, 0, 0}, sol, EPSILON)
+	cuts, err := lp.GomoryCuts()
+	assert.NoError(t, err)
+	assert.Len(t, cuts, 2)
+	for _, cut := range cuts {
+		assert.Equal(t, float64(1), cut[0])
+		// violated by the current solution
+		lhs := 0.0
+		for j, x := range sol {
+			lhs += cut[j+1] * x
+		}
+		assert.Less(t, lhs, cut[0])
+		// only nonbasic variables have nonzero coefficients
+		for _, v := range lp.Basis() {
+			assert.Equal(t, float64(0), cut[v+1])
+		}
+		// integer points such as (2, 2) satisfy the cut; slack variables
+		// are 1 - 2 + 2, 12 - 6 - 4 and 12 - 4 - 6
+		assert.GreaterOrEqual(t, cut[3]*1+cut[4]*2+cut[5]*2+cut[1]*2+cut[2]*2, float64(1))
+	}
+
+	// no cuts for integral solutions
+	lp = integerLP()
+	lp.SetContinuous(0, 1)
+	lp.Optimize()
+	cuts, _ = lp.GomoryCuts()
+	assert.Empty(t, cuts)
+}
+
+func TestLP_OptimizeGomory(t *testing.T) {
+	lp := integerLP()
+	added, err := lp.OptimizeGomory(0)
+	assert.NoError(t, err)
+	assert.Greater(t, added, 0)
+	assert.Equal(t, 3+added, lp.NumConstraints())
+	assert.InDelta(t, 4, lp.ObjectiveValue(), 1e-6)
+	sol, _ := lp.Solution()
+	for _, x := range sol[:2] {
+		assert.InDelta(t, math.Round(x), x, 1e-6)
+	}
+
+	lp = integerLP()
+	added, err = lp.OptimizeGomory(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Less(t, lp.ObjectiveValue(), 4.8)
+
+	// the cuts of max 6x1 + 11x2 st. 2x1 + 11x2 <= 132, 14x1 + 21x2 <= 62 do
+	// not reach an integral solution within the default number of rounds
+	gomoryLP := func() *LP {
+		lp := NewLP()
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 6, 11)
+		lp.AddConstraintGeq(132, 2, 11)
+		lp.AddConstraintGeq(62, 14, 21)
+		lp.SetInteger(0, 1)
+		return lp
+	}
+	lp = gomoryLP()
+	added, err = lp.OptimizeGomory(0)
+	assert.NoError(t, err)
+	expected, _ := gomoryLP().OptimizeGomory(gomoryRounds)
+	assert.Equal(t, expected, added)
+	cuts, _ := lp.GomoryCuts()
+	assert.NotEmpty(t, cuts)
+}
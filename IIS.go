@@ -0,0 +1,49 @@
+package sago
+
+//ComputeIIS returns the indices of an irreducible infeasible subsystem of the
+//constraints of an infeasible LP: a set of constraints that is infeasible,
+//while removing any one of them makes it feasible. The bounds of the variables
+//are kept with every subsystem, so the IIS is empty if they contradict each
+//other. The constraints not in the support of the Farkas certificate are
+//dropped first, then the rest is filtered one constraint at a time by solving
+//phase I without it. Returns an InvalidInputError if the LP is feasible.
+func (lp *LP) ComputeIIS() ([]int, error) {
+	work := lp.Copy()
+	work.reset()
+	if work.Feasible() {
+		return nil, InvalidInputError{s: "LP is feasible"}
+	}
+	indices := make([]int, work.NumConstraints())
+	for i := range indices {
+		indices[i] = i
+	}
+
+	// the constraints combined by the certificate are infeasible on their own
+	if farkas := work.infeasibleError().Farkas; farkas != nil {
+		candidate := work.Copy()
+		var kept []int
+		for i := len(indices) - 1; i >= 0; i-- {
+			if Feq(farkas[i], 0, lp.GetTolerances().Zero) {
+				candidate.RemoveConstraint(i)
+			} else {
+				kept = append([]int{indices[i]}, kept...)
+			}
+		}
+		if !candidate.Feasible() {
+			work, indices = candidate, kept
+		}
+	}
+
+	// deletion filter
+	for i := 0; i < len(indices); {
+		candidate := work.Copy()
+		candidate.RemoveConstraint(i)
+		if candidate.Feasible() {
+			i++
+			continue
+		}
+		work = candidate
+		indices = append(indices[:i], indices[i+1:]...)
+	}
+	return indices, nil
+}
@@ -0,0 +1,91 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// the LP with only the constraints at the given indices
+func subsystem(lp *LP, indices []int) *LP {
+	keep := map[int]bool{}
+	for _, i := range indices {
+		keep[i] = true
+	}
+	c := lp.Copy()
+	c.reset()
+	for i := c.NumConstraints() - 1; i >= 0; i-- {
+		if !keep[i] {
+			c.RemoveConstraint(i)
+		}
+	}
+	return c
+}
+
+func TestLP_ComputeIIS(t *testing.T) {
+	// x <= 1, y <= 5, x >= 2, x + y <= 10
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.AddConstraintGeq(5, 0, 1)
+	lp.AddConstraintLeq(2, 1)
+	lp.AddConstraintGeq(10, 1, 1)
+	lp.Optimize()
+	iis, err := lp.ComputeIIS()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, iis)
+	assert.Equal(t, 4, lp.NumConstraints())
+
+	// x + y <= 2, z <= 1, x >= 1.5, y >= 1.5, x + y + z >= 0
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1, 1)
+	lp.AddConstraintGeq(2, 1, 1)
+	lp.AddConstraintGeq(1, 0, 0, 1)
+	lp.AddConstraintLeq(1.5, 1)
+	lp.AddConstraintLeq(1.5, 0, 1)
+	lp.AddConstraintLeq(0, 1, 1, 1)
+	iis, err = lp.ComputeIIS()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 3}, iis)
+
+	// the bounds are part of every subsystem
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(4, 0, 1)
+	lp.AddConstraintLeq(2, 1)
+	lp.SetBounds(0, 0, 1)
+	iis, err = lp.ComputeIIS()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, iis)
+
+	lp.SetBounds(0, 0, 3)
+	_, err = lp.ComputeIIS()
+	assert.IsType(t, InvalidInputError{}, err)
+}
+
+func TestLP_ComputeIISRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	found := 0
+	for k := 0; k < 20; k++ {
+		lp := randomLP(r, 6, 5)
+		for i := 0; i < 3; i++ {
+			a := make([]float64, 5)
+			for j := range a {
+				a[j] = float64(r.Intn(5) + 1)
+			}
+			lp.AddConstraintLeq(float64(r.Intn(150)+50), a...)
+		}
+		if lp.Feasible() {
+			continue
+		}
+		found++
+		iis, err := lp.ComputeIIS()
+		assert.NoError(t, err)
+		assert.False(t, subsystem(lp, iis).Feasible())
+		for k := range iis {
+			rest := append(append([]int{}, iis[:k]...), iis[k+1:]...)
+			assert.True(t, subsystem(lp, rest).Feasible())
+		}
+	}
+	assert.Greater(t, found, 0)
+}
@@ -1,6 +1,10 @@
 package sago
 
-import "fmt"
+import (
+	"fmt"
+	"log/slog"
+	"math"
+)
 
 const (
 	//MAXIMIZE objective for LPs
@@ -9,20 +13,80 @@ const (
 	MINIMIZE
 )
 
+const (
+	//TABLEAU engine pivots on the full tableau
+	TABLEAU = iota
+	//REVISED engine keeps only the constraints and an LU factorization of the
+	//basis, updated with the Forrest-Tomlin update at every pivot
+	REVISED
+	//EXACT engine pivots on a tableau of fractions with Bland's rule
+	EXACT
+	//SPARSE engine is the REVISED engine with the basis inverse kept as a
+	//product of sparse eta matrices instead of LU factors
+	SPARSE
+)
+
+const (
+	constraintEq    = iota // b = a1x1 + a2x2 + ...
+	constraintLeq          // b <= a1x1 + a2x2 + ...
+	constraintGeq          // b >= a1x1 + a2x2 + ...
+	constraintRange        // b >= a1x1 + a2x2 + ... >= b - upper bound of the slack
+)
+
+const (
+	//INACTIVE constraint holds with slack on both sides
+	INACTIVE = 0
+	//ATLOWER constraint holds with equality at its lower limit
+	ATLOWER = 1
+	//ATUPPER constraint holds with equality at its upper limit
+	ATUPPER = 2
+)
+
 //LP is a data structure that represents a linear program
 type LP struct {
+	basis            []int // basic variable of each constraint
+	bounded          *boundedColumns
+	bounds           map[int]Range // bounds of the variables other than x >= 0
+	callback         Callback
+	control          *solveControl // set while OptimizeContext runs
+	dirty            bool          // tableau has been pivoted; original holds the LP as built
+	engine           int           // TABLEAU, REVISED, EXACT or SPARSE
+	exact            *exactSimplex // final tableau of the EXACT engine
+	farkas           []float64     // certificate of infeasibility found by phase I
 	feasible         bool
 	feasibilityKnown bool
+	binaries         map[int]bool
+	integers         map[int]bool
+	logger           *slog.Logger
+	mipOptions       MIPOptions
 	objective        int // MAXIMIZE or MINIMIZE
 	optimal          bool
+	original         tableau // tableau as built, before it was pivoted
+	pivotRule        PivotRule
+	presolve         bool
+	revised          *revisedSimplex // final state of the REVISED or SPARSE engine whose constraint rows are not written yet
+	ray              []float64       // improving direction of an unbounded LP over the variables of the tableau
+	rows             []rowInfo
+	scaling          int // NOSCALING, GEOMETRIC or EQUILIBRATION
 	solved           bool
+	sparse           *sparseConstraints // constraints added with AddConstraintSparse, not yet in the tableau
 	tableau          tableau
+	tolerances       Tolerances
+	trace            *Trace
 	unbounded        bool
+	warm             bool // tableau holds an optimal basis for the constraints in basis
 	width            int
 }
 
 type tableau [][]float64
 
+// describes how a constraint was added to the LP
+type rowInfo struct {
+	kind  int     // constraintEq, constraintLeq, constraintGeq or constraintRange
+	sign  float64 // -1 if the constraint was negated to keep b nonnegative
+	slack int     // index of the slack variable, or -1 for equality constraints
+}
+
 //NewLP reates a new LP
 func NewLP() *LP {
 	return &LP{
@@ -33,15 +97,60 @@ func NewLP() *LP {
 		feasible:         false,
 		feasibilityKnown: false,
 		objective:        MAXIMIZE,
+		engine:           TABLEAU,
+		mipOptions:       MIPOptions{NodeSelection: BESTBOUND, Gap: 1e-4},
 		width:            0,
 	}
 }
 
+//Copy returns a deep copy of the LP. The copy shares the pivot rule of the LP.
+func (lp *LP) Copy() *LP {
+	c := *lp
+	c.tableau = lp.tableau.copy()
+	if lp.original != nil {
+		c.original = lp.original.copy()
+	}
+	c.rows = append([]rowInfo(nil), lp.rows...)
+	c.basis = append([]int(nil), lp.basis...)
+	c.farkas = append([]float64(nil), lp.farkas...)
+	c.ray = append([]float64(nil), lp.ray...)
+	c.integers = copyMarks(lp.integers)
+	c.binaries = copyMarks(lp.binaries)
+	if lp.bounds != nil {
+		c.bounds = make(map[int]Range, len(lp.bounds))
+		for k, v := range lp.bounds {
+			c.bounds[k] = v
+		}
+	}
+	c.bounded = lp.bounded.copy()
+	c.sparse = lp.sparse.copy()
+	c.revised = lp.revised.copy(&c)
+	c.trace = nil
+	return &c
+}
+
 //GetObjective returns the objective of the LP (one of { MAXIMIZE, MINIMIZE })
 func (lp *LP) GetObjective() int {
 	return lp.objective
 }
 
+//GetEngine returns the simplex engine used by Optimize (one of { TABLEAU, REVISED, EXACT, SPARSE })
+func (lp *LP) GetEngine() int {
+	return lp.engine
+}
+
+//SetEngine sets the simplex engine used by Optimize (one of { TABLEAU, REVISED, EXACT, SPARSE }).
+//The EXACT engine reads each coefficient as the shortest decimal that rounds to
+//it; ExactSolution and ExactObjectiveValue return its results as fractions.
+//The SPARSE engine suits LPs with few nonzero coefficients per constraint,
+//especially when they are added with AddConstraintSparse.
+//The EXACT engine substitutes nonnegative variables for variables with bounds
+//other than x >= 0; the REVISED and SPARSE engines leave such LPs to the TABLEAU
+//engine.
+func (lp *LP) SetEngine(engine int) {
+	lp.engine = engine
+}
+
 //Solution returns the solution vector of the LP i.e. the value X=(x1, x2, ...)
 //for the objective function MAX/MIN c1 x1 + x2 x2 + ...
 func (lp *LP) Solution() ([]float64, error) {
@@ -49,44 +158,37 @@ func (lp *LP) Solution() ([]float64, error) {
 		return []float64{}, SolutionUnavailableError{"LP is unsolved; try optimizing LP first!"}
 	}
 	if lp.unbounded {
-		return []float64{}, NoSolutionError{"LP is unbounded"}
+		return []float64{}, lp.unboundedError()
 	}
 	if !lp.feasible {
-		return []float64{}, NoSolutionError{"LP is infeasible"}
+		return []float64{}, lp.infeasibleError()
 	}
-	var sol []float64
-	var vals []float64
+	return lp.basicSolution(), nil
+}
 
-	// b
-	for _, row := range lp.tableau[1:] {
-		vals = append(vals, row[0])
+// the values of the variables in the current basic solution
+func (lp *LP) basicSolution() []float64 {
+	basis := lp.basis
+	if basis == nil {
+		basis = lp.findBasis()
 	}
-
-	for c, v := range lp.tableau[0][1:] {
-		if v != 0 {
-			// Skip trying to find a 1 in column; this entry in the solution vector is 0
-			goto AddZero
+	sol := make([]float64, lp.Width()-1)
+	for r, v := range basis {
+		if v < 0 {
+			continue
 		}
-
-		// Try to find 1 in column
-		for r := range lp.tableau[1:] {
-			if vals == nil {
-				return []float64{}, fmt.Errorf("an unexpected error has occurred")
-			}
-			if Feq(lp.tableau[r+1][c+1], 1, EPSILON) {
-				// found 1, add value to solution vector, then skip to next iteration of outer loop
-				sol = append(sol, vals[r])
-				goto Next
-			}
+		if lp.revised != nil {
+			sol[v] = lp.revised.xB[r]
+		} else {
+			sol[v] = lp.tableau[r+1][0]
 		}
-
-	AddZero:
-		sol = append(sol, 0)
-
-	Next:
 	}
-
-	return sol, nil
+	if lp.bounded != nil {
+		for j := range sol {
+			sol[j] = lp.bounded.value(j, sol[j])
+		}
+	}
+	return sol
 }
 
 //ObjectiveFunctionVector returns the vector C=(c1, c2, ...) in the objective
@@ -97,13 +199,17 @@ func (lp *LP) ObjectiveFunctionVector() []float64 {
 
 //ObjectiveValue is the objective value of the *current* LP
 func (lp *LP) ObjectiveValue() float64 {
+	if lp.dirty {
+		return lp.sense() * lp.tableau[0][0]
+	}
 	return (lp.tableau)[0][0]
 }
 
 //SetObjectiveFunction sets the objective function
 //MAXIMIZE/MINIMIZE z = c1x1 + c2x2 + ... for the LP
 func (lp *LP) SetObjectiveFunction(objective int, z float64, coefficients ...float64) {
-	lp.tableau[0] = append([]float64{z}, coefficients...)
+	lp.reset()
+	lp.tableau[0] = extend(append([]float64{z}, coefficients...), lp.width)
 	lp.objective = objective
 	fnLen := len(lp.tableau[0])
 	if fnLen > lp.width {
@@ -113,49 +219,117 @@ func (lp *LP) SetObjectiveFunction(objective int, z float64, coefficients ...flo
 
 //NumConstraints returns the number of constraints in the LP
 func (lp *LP) NumConstraints() int {
-	return len(lp.tableau) - 1
+	return len(lp.tableau) - 1 + lp.sparse.len()
 }
 
 //AddConstraintEq  adds an equality constraint
 //for b = a1x1 + a2x2 + ... + anxn, coefficients should be (a1, a2, ..., an)
 func (lp *LP) AddConstraintEq(b float64, coefficients ...float64) {
-	constraint := extend(append([]float64{b}, coefficients...), lp.width)
-	if Flt(b, 0, EPSILON) {
-		constraint = ScalarVectorMultiply(-1, constraint)
-	}
-	if len(constraint) > lp.width {
-		lp.increaseWidth(len(constraint))
-	}
-	lp.tableau = append(lp.tableau, constraint)
+	lp.addConstraint(rowInfo{kind: constraintEq, slack: -1}, b, coefficients)
 }
 
 //AddConstraintGeq is like AddConstraintEq but for >= constraints
 func (lp *LP) AddConstraintGeq(b float64, coefs ...float64) {
 	constraint := extend(coefs, lp.width-1)
 	constraint = append(constraint, 1)
-	lp.AddConstraintEq(b, constraint...)
+	lp.addConstraint(rowInfo{kind: constraintGeq, slack: len(constraint) - 1}, b, constraint)
 }
 
 //AddConstraintLeq is like AddConstraintEq but for <= constraints
 func (lp *LP) AddConstraintLeq(b float64, coefs ...float64) {
 	constraint := extend(coefs, lp.width-1)
 	constraint = append(constraint, -1)
-	lp.AddConstraintEq(b, constraint...)
+	lp.addConstraint(rowInfo{kind: constraintLeq, slack: len(constraint) - 1}, b, constraint)
+}
+
+//AddConstraintRange adds the constraint lo <= a1x1 + a2x2 + ... <= hi as a single
+//row b = a1x1 + a2x2 + ... + s with b = hi and a slack variable 0 <= s <= hi - lo.
+//Use ActiveSide to find out which limit holds with equality in the solution.
+//A range without upper limit is added with AddConstraintLeq.
+func (lp *LP) AddConstraintRange(lo, hi float64, coefs ...float64) {
+	if math.IsInf(hi, 1) {
+		lp.AddConstraintLeq(lo, coefs...)
+		return
+	}
+	constraint := extend(coefs, lp.width-1)
+	constraint = append(constraint, 1)
+	slack := len(constraint) - 1
+	if lp.bounds == nil {
+		lp.bounds = map[int]Range{}
+	}
+	lp.bounds[slack] = Range{Lower: 0, Upper: hi - lo}
+	lp.addConstraint(rowInfo{kind: constraintRange, slack: slack}, hi, constraint)
+}
+
+//ActiveSide returns ATLOWER, ATUPPER or INACTIVE depending on which limit of
+//the i-th constraint holds with equality in the solution; equality constraints
+//and ranges with lo = hi are ATLOWER|ATUPPER
+func (lp *LP) ActiveSide(i int) (int, error) {
+	sol, err := lp.Solution()
+	if err != nil {
+		return INACTIVE, err
+	}
+	if i < 0 || i >= lp.NumConstraints() {
+		return INACTIVE, InvalidInputError{s: fmt.Sprintf("no constraint %d", i)}
+	}
+	info := lp.rowInfo(i)
+	if info.slack < 0 {
+		return ATLOWER | ATUPPER, nil
+	}
+	s := sol[info.slack]
+	tol := lp.GetTolerances().PrimalFeasibility
+	side := INACTIVE
+	switch info.kind {
+	case constraintGeq:
+		if Feq(s, 0, tol) {
+			side = ATUPPER
+		}
+	case constraintLeq:
+		if Feq(s, 0, tol) {
+			side = ATLOWER
+		}
+	case constraintRange:
+		_, upper := lp.Bounds(info.slack)
+		if Feq(s, 0, tol) {
+			side |= ATUPPER
+		}
+		if Feq(s, upper, tol) {
+			side |= ATLOWER
+		}
+	}
+	return side, nil
+}
+
+//SlackColumn returns the index of the slack variable of the i-th constraint,
+//or -1 if the constraint was added through AddConstraintEq
+func (lp *LP) SlackColumn(i int) int {
+	return lp.rowInfo(i).slack
 }
 
 //ListConstraints returns the tail of the tableau
 func (lp *LP) ListConstraints() [][]float64 {
+	lp.densify()
 	return (lp.tableau)[1:]
 }
 
 //RemoveConstraint removes the i-th constraint from the LP
 func (lp *LP) RemoveConstraint(i int) {
+	lp.reset()
+	lp.densify()
 	lp.tableau = append((lp.tableau)[:i+1], (lp.tableau)[i+2:]...)
+	if i < len(lp.rows) {
+		lp.rows = append(lp.rows[:i], lp.rows[i+1:]...)
+	}
+	lp.basis = nil
 }
 
 //ClearConstraints removes all constraints
 func (lp *LP) ClearConstraints() {
+	lp.reset()
 	lp.tableau = (lp.tableau)[0:1]
+	lp.sparse = nil
+	lp.rows = nil
+	lp.basis = nil
 }
 
 //Solved returns true if the simplex algorithm has been run on the LP
@@ -173,6 +347,94 @@ func (lp *LP) Width() int {
 	return len(lp.tableau[0])
 }
 
+func (lp *LP) addConstraint(info rowInfo, b float64, coefficients []float64) {
+	lp.densify()
+	if !lp.warm {
+		lp.reset()
+	}
+	constraint := extend(append([]float64{b}, coefficients...), lp.width)
+	info.sign = 1
+	if Flt(b, 0, lp.GetTolerances().Zero) {
+		constraint = ScalarVectorMultiply(-1, constraint)
+		info.sign = -1
+	}
+	if len(constraint) > lp.width {
+		lp.increaseWidth(len(constraint))
+	}
+	for len(lp.rows) < lp.NumConstraints() {
+		lp.rows = append(lp.rows, lp.rowInfo(len(lp.rows)))
+	}
+	lp.tableau = append(lp.tableau, constraint)
+	lp.rows = append(lp.rows, info)
+	if !lp.dirty {
+		lp.basis = nil
+		return
+	}
+	// keep the basis of the previous constraints so Optimize can warm start
+	lp.original = append(lp.original, append([]float64{}, constraint...))
+	lp.solved = false
+	lp.optimal = false
+	lp.unbounded = false
+	lp.feasibilityKnown = false
+}
+
+// takes a copy of the LP as built before the tableau is pivoted
+func (lp *LP) snapshot() {
+	if !lp.dirty {
+		lp.original = lp.tableau.copy()
+		lp.dirty = true
+	}
+}
+
+// restores the LP as built, discarding the work of previous optimizations
+func (lp *LP) reset() {
+	if !lp.dirty {
+		return
+	}
+	lp.tableau = lp.original.copy()
+	lp.basis = nil
+	lp.bounded = nil
+	lp.exact = nil
+	lp.revised = nil
+	lp.farkas = nil
+	lp.ray = nil
+	lp.dirty = false
+	lp.warm = false
+	lp.solved = false
+	lp.optimal = false
+	lp.unbounded = false
+	lp.feasible = false
+	lp.feasibilityKnown = false
+}
+
+// describes the i-th constraint; constraints of a tableau that was built
+// directly are treated as equality constraints
+func (lp *LP) rowInfo(i int) rowInfo {
+	if i < len(lp.rows) {
+		return lp.rows[i]
+	}
+	return rowInfo{kind: constraintEq, sign: 1, slack: -1}
+}
+
+func copyMarks(m map[int]bool) map[int]bool {
+	if m == nil {
+		return nil
+	}
+	c := make(map[int]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (t tableau) copy() tableau {
+	c := make(tableau, len(t))
+	for i, row := range t {
+		c[i] = append([]float64{}, row...)
+	}
+	return c
+}
+
 func extend(A []float64, length int) []float64 {
 	for len(A) < length {
 		A = append(A, 0)
@@ -180,8 +442,21 @@ func extend(A []float64, length int) []float64 {
 	return A
 }
 
+// 1 for MAXIMIZE and -1 for MINIMIZE; the simplex algorithm maximizes the
+// objective function times the sense
+func (lp *LP) sense() float64 {
+	if lp.objective == MINIMIZE {
+		return -1
+	}
+	return 1
+}
+
+// puts the objective function z + cx as built in the form the simplex algorithm
+// works on, (sz, -sc) for the sense s, so that tableau[0][0] is s times the
+// objective value
 func (lp *LP) negateObjectiveFunction() {
-	lp.tableau[0] = ScalarVectorMultiply(-1, lp.tableau[0])
+	s := lp.sense()
+	lp.tableau[0] = ScalarVectorMultiply(-s, lp.tableau[0])
 	lp.tableau[0][0] *= -1
 }
 
@@ -190,6 +465,11 @@ func (lp *LP) increaseWidth(length int) {
 	for i := range lp.tableau {
 		lp.tableau[i] = extend(lp.tableau[i], length)
 	}
+	if lp.dirty {
+		for i := range lp.original {
+			lp.original[i] = extend(lp.original[i], length)
+		}
+	}
 }
 
 func (lp *LP) auxLP() *LP {
@@ -215,6 +495,7 @@ func (lp *LP) auxLP() *LP {
 			matrix.tableau[i+1] = append(matrix.tableau[i+1], 0)
 		}
 		matrix.tableau[i+1][variables+i] = 1
+		matrix.basis = append(matrix.basis, variables+i-1)
 	}
 
 	return matrix
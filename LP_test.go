@@ -2,6 +2,7 @@ package sago
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -69,6 +70,22 @@ func TestLP_AddConstraintGeq(t *testing.T) {
 	assert.Equal(t, tableau{{0, 0, 0, 0, 0, 0}, {1, 2, 3, 1, -1, 0}, {4, 5, 6, 0, 0, -1}}, lp.tableau)
 }
 
+func TestLP_AddConstraintRange(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 1, 2, 3)
+	lp.AddConstraintRange(1, 3, 1, 2)
+	assert.Equal(t, tableau{{1, 2, 3, 0}, {3, 1, 2, 1}}, lp.tableau)
+	lower, upper := lp.Bounds(2)
+	assert.Equal(t, []float64{0, 2}, []float64{lower, upper})
+	lp.AddConstraintRange(-4, -2, 5, 6)
+	assert.Equal(t, tableau{{1, 2, 3, 0, 0}, {3, 1, 2, 1, 0}, {2, -5, -6, 0, -1}}, lp.tableau)
+	lower, upper = lp.Bounds(3)
+	assert.Equal(t, []float64{0, 2}, []float64{lower, upper})
+
+	lp.AddConstraintRange(1, math.Inf(1), 1)
+	assert.Equal(t, []float64{1, 1, 0, 0, 0, -1}, lp.tableau[3])
+}
+
 func TestLP_ListConstraints(t *testing.T) {
 	lp := NewLP()
 	lp.SetObjectiveFunction(MAXIMIZE, 1, 2, 3)
@@ -141,3 +158,81 @@ func TestLP_Solution(t *testing.T) {
 	actual, _ = lp.Solution()
 	assert.Equal(t, []float64{65.76374332608265, 0, 0, 0, 10.038553835008601, 0, 0, 0}, actual)
 }
+
+func TestLP_ActiveSide(t *testing.T) {
+	// max x + y st. x <= 3, y <= 5, -4 <= x - y <= -2
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(3, 1)
+	lp.AddConstraintGeq(5, 0, 1)
+	lp.AddConstraintRange(-4, -2, 1, -1)
+	_, err := lp.ActiveSide(2)
+	assert.Error(t, err)
+	lp.Optimize()
+	assert.Equal(t, 3, lp.NumConstraints())
+	assert.InDelta(t, 8, lp.ObjectiveValue(), EPSILON)
+	sides := []int{ATUPPER, ATUPPER, ATUPPER}
+	for i, expected := range sides {
+		side, err := lp.ActiveSide(i)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, side)
+	}
+
+	// max y - 2x moves x - y to its lower limit
+	lp.SetObjectiveFunction(MAXIMIZE, 0, -2, 1, 0, 0, 0)
+	lp.Optimize()
+	assert.InDelta(t, 4, lp.ObjectiveValue(), EPSILON)
+	sol, _ := lp.Solution()
+	assert.InDeltaSlice(t, []float64{0, 4}, sol[:2], EPSILON)
+	side, _ := lp.ActiveSide(2)
+	assert.Equal(t, ATLOWER, side)
+	side, _ = lp.ActiveSide(1)
+	assert.Equal(t, INACTIVE, side)
+
+	// 2 <= x + y <= 2 holds at both limits
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintRange(2, 2, 1, 1)
+	lp.AddConstraintEq(1, 0, 1)
+	lp.Optimize()
+	assert.InDelta(t, 1, lp.ObjectiveValue(), EPSILON)
+	side, _ = lp.ActiveSide(0)
+	assert.Equal(t, ATLOWER|ATUPPER, side)
+	side, _ = lp.ActiveSide(1)
+	assert.Equal(t, ATLOWER|ATUPPER, side)
+	_, err = lp.ActiveSide(2)
+	assert.Error(t, err)
+
+	// 3 <= x <= 2 is infeasible
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintRange(3, 2, 1)
+	assert.False(t, lp.Feasible())
+}
+
+func TestLP_AddConstraintRangeWarmStart(t *testing.T) {
+	lp := sensitivityLP()
+	lp.Optimize()
+	assert.True(t, lp.Optimal())
+	cold := lp.Copy()
+	cold.reset()
+
+	// -4 <= x1 - x2 <= -1 and 6 <= x1 + x2 <= 7
+	lp.AddConstraintRange(-4, -1, 1, -1)
+	lp.AddConstraintRange(6, 7, 1, 1)
+	cold.AddConstraintRange(-4, -1, 1, -1)
+	cold.AddConstraintRange(6, 7, 1, 1)
+	lp.Optimize()
+	cold.Optimize()
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 32, lp.ObjectiveValue(), EPSILON)
+	assert.InDelta(t, cold.ObjectiveValue(), lp.ObjectiveValue(), EPSILON)
+	sol, _ := lp.Solution()
+	expected, _ := cold.Solution()
+	assert.InDeltaSlice(t, expected, sol, 1e-9)
+	assert.InDeltaSlice(t, []float64{1.5, 5.5}, sol[:2], 1e-9)
+	side, _ := lp.ActiveSide(3)
+	assert.Equal(t, ATLOWER, side)
+	side, _ = lp.ActiveSide(4)
+	assert.Equal(t, ATUPPER, side)
+}
@@ -0,0 +1,175 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+)
+
+// LU factorization PB = LU of a square matrix B with partial pivoting, kept up
+// to date by Forrest-Tomlin updates when a column of B is replaced: after the
+// updates R_k ... R_1 L^-1 PB = U, where each R is a row eta and U is upper
+// triangular once its rows and columns are both permuted to order.
+type luFactorization struct {
+	lower [][]float64 // L below the diagonal; its unit diagonal is not stored
+	upper [][]float64 // U
+	perm  []int       // row i of PB is row perm[i] of B
+	order []int       // U is triangular in rows and columns order[0], order[1], ...
+	etas  []rowEta
+}
+
+// row p of R - I for a row eta R, with the entries in the order they were
+// eliminated
+type rowEta struct {
+	p   int
+	row sparseVector
+}
+
+// factorizes the square matrix, which is singular if a pivot is within
+// tolerance of 0; the matrix is not modified
+func factorize(matrix [][]float64, tolerance float64) (*luFactorization, error) {
+	n := len(matrix)
+	lu := make([][]float64, n)
+	f := &luFactorization{lower: make([][]float64, n), upper: make([][]float64, n), perm: make([]int, n), order: make([]int, n)}
+	for i := range matrix {
+		lu[i] = append([]float64{}, matrix[i]...)
+		f.perm[i] = i
+		f.order[i] = i
+	}
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[pivot][k]) {
+				pivot = i
+			}
+		}
+		if Feq(lu[pivot][k], 0, tolerance) {
+			return nil, InvalidInputError{s: fmt.Sprintf("matrix is singular at column %d", k)}
+		}
+		lu[k], lu[pivot] = lu[pivot], lu[k]
+		f.perm[k], f.perm[pivot] = f.perm[pivot], f.perm[k]
+		for i := k + 1; i < n; i++ {
+			factor := lu[i][k] / lu[k][k]
+			lu[i][k] = factor
+			if factor == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= factor * lu[k][j]
+			}
+		}
+	}
+	for i, row := range lu {
+		f.lower[i] = make([]float64, n)
+		f.upper[i] = make([]float64, n)
+		copy(f.lower[i][:i], row[:i])
+		copy(f.upper[i][i:], row[i:])
+	}
+	return f, nil
+}
+
+// R_k ... R_1 L^-1 P r
+func (f *luFactorization) transform(r []float64) []float64 {
+	n := len(f.lower)
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x[i] = r[f.perm[i]]
+		for j := 0; j < i; j++ {
+			x[i] -= f.lower[i][j] * x[j]
+		}
+	}
+	for _, e := range f.etas {
+		x[e.p] += e.row.dot(x)
+	}
+	return x
+}
+
+// solves Bx = r
+func (f *luFactorization) solve(r []float64) []float64 {
+	x := f.transform(r)
+	// Ux = y, last row of the order first
+	for k := len(f.order) - 1; k >= 0; k-- {
+		i := f.order[k]
+		for _, j := range f.order[k+1:] {
+			x[i] -= f.upper[i][j] * x[j]
+		}
+		x[i] /= f.upper[i][i]
+	}
+	return x
+}
+
+// solves B^T y = c
+func (f *luFactorization) solveTranspose(c []float64) []float64 {
+	n := len(f.lower)
+	z := append([]float64{}, c...)
+	// U^T z = c, first row of the order first
+	for k, i := range f.order {
+		for _, j := range f.order[:k] {
+			z[i] -= f.upper[j][i] * z[j]
+		}
+		z[i] /= f.upper[i][i]
+	}
+	// R_1^T ... R_k^T z
+	for k := len(f.etas) - 1; k >= 0; k-- {
+		e := f.etas[k]
+		zp := z[e.p]
+		if zp == 0 {
+			continue
+		}
+		for l, j := range e.row.index {
+			z[j] += e.row.value[l] * zp
+		}
+	}
+	// L^T w = z
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			z[i] -= f.lower[j][i] * z[j]
+		}
+	}
+	// y = P^T w
+	y := make([]float64, n)
+	for i, p := range f.perm {
+		y[p] = z[i]
+	}
+	return y
+}
+
+// replaces column p of B by a (Forrest-Tomlin): column p of U becomes the
+// spike R L^-1 P a and moves to the end of the order, and row p, which moves
+// with it, is eliminated by the rows that now precede it, giving a new row eta.
+// The factorization is unusable after an error, which means the new diagonal
+// entry is within tolerance of 0.
+func (f *luFactorization) update(p int, a []float64, tolerance float64) error {
+	spike := f.transform(a)
+	for i, row := range f.upper {
+		row[p] = spike[i]
+	}
+	k := 0
+	for f.order[k] != p {
+		k++
+	}
+	later := append([]int{}, f.order[k+1:]...)
+	f.order = append(append(f.order[:k], later...), p)
+
+	row := f.upper[p]
+	e := rowEta{p: p}
+	for l, j := range later {
+		if row[j] == 0 {
+			continue
+		}
+		factor := row[j] / f.upper[j][j]
+		for _, c := range later[l+1:] {
+			row[c] -= factor * f.upper[j][c]
+		}
+		row[p] -= factor * f.upper[j][p]
+		row[j] = 0
+		e.row.index = append(e.row.index, j)
+		e.row.value = append(e.row.value, -factor)
+	}
+	if Feq(row[p], 0, tolerance) {
+		return InvalidInputError{s: fmt.Sprintf("updated matrix is singular at column %d", p)}
+	}
+	if e.row.index != nil {
+		f.etas = append(f.etas, e)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestFactorize(t *testing.T) {
+	matrix := [][]float64{
+		{0, 2, 1},
+		{1, 0, 0},
+		{3, 2, 2},
+	}
+	f, err := factorize(matrix, EPSILON)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 2, 1}, matrix[0])
+
+	// B (1, 2, 3) = (7, 1, 13)
+	assert.InDeltaSlice(t, []float64{1, 2, 3}, f.solve([]float64{7, 1, 13}), EPSILON)
+	// B^T (1, 2, 3) = (11, 8, 7)
+	assert.InDeltaSlice(t, []float64{1, 2, 3}, f.solveTranspose([]float64{11, 8, 7}), EPSILON)
+
+	_, err = factorize([][]float64{{1, 2}, {2, 4}}, EPSILON)
+	assert.Error(t, err)
+}
+
+func TestLuFactorization_update(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const n = 8
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		for j := range matrix[i] {
+			matrix[i][j] = float64(r.Intn(21) - 10)
+		}
+	}
+	f, err := factorize(matrix, EPSILON)
+	assert.NoError(t, err)
+	for k := 0; k < 30; k++ {
+		// replace a column of B by a random column
+		p := r.Intn(n)
+		a := make([]float64, n)
+		old := make([]float64, n)
+		for i := range a {
+			a[i] = float64(r.Intn(21) - 10)
+			old[i], matrix[i][p] = matrix[i][p], a[i]
+		}
+		if _, err := factorize(matrix, 1e-6); err != nil {
+			for i := range old {
+				matrix[i][p] = old[i]
+			}
+			continue
+		}
+		assert.NoError(t, f.update(p, a, EPSILON), k)
+
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = float64(r.Intn(10))
+		}
+		b := make([]float64, n)
+		c := make([]float64, n)
+		for i := range matrix {
+			for j := range matrix {
+				b[i] += matrix[i][j] * x[j]
+				c[j] += matrix[i][j] * x[i]
+			}
+		}
+		// Bx = b and B^T x = c
+		assert.InDeltaSlice(t, x, f.solve(b), 1e-6, k)
+		assert.InDeltaSlice(t, x, f.solveTranspose(c), 1e-6, k)
+	}
+	assert.NotEmpty(t, f.etas)
+
+	// replacing a column by a multiple of another makes B singular
+	f, _ = factorize([][]float64{{1, 0}, {0, 1}}, EPSILON)
+	assert.Error(t, f.update(1, []float64{2, 0}, EPSILON))
+}
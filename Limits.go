@@ -0,0 +1,122 @@
+package sago
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+//SolveOptions limits the work of OptimizeContext
+type SolveOptions struct {
+	IterationLimit int           // maximum number of simplex iterations; 0 for no limit
+	TimeLimit      time.Duration // 0 for no limit
+}
+
+// limits of OptimizeContext, shared by the LP and its auxiliary LP
+type solveControl struct {
+	ctx           context.Context
+	deadline      time.Time // zero for no time limit
+	maxIterations int
+	iterations    int
+	reached       bool   // a limit stopped the simplex algorithm
+	status        Status // the limit that was reached
+	phase         int    // PHASEI, PHASEII or DUALSIMPLEX
+	callback      Callback
+}
+
+//OptimizeContext is like Optimize, but checks before every pivot whether ctx is
+//done or a limit of options is reached, and stops the simplex algorithm if so
+//with ITERATIONLIMIT, TIMELIMIT or CANCELED as the status of the result. An LP
+//that stopped is not Solved; if it stopped in phase II, Basis returns the last
+//basis reached, which is feasible, and the result holds its solution.
+func (lp *LP) OptimizeContext(ctx context.Context, options SolveOptions) *Result {
+	start := time.Now()
+	control := &solveControl{
+		ctx:           ctx,
+		maxIterations: options.IterationLimit,
+		phase:         PHASEII,
+		callback:      lp.progressCallback(),
+	}
+	if options.TimeLimit > 0 {
+		control.deadline = start.Add(options.TimeLimit)
+	}
+	if lp.logger != nil {
+		lp.logger.Info("simplex started",
+			slog.Int("constraints", lp.NumConstraints()),
+			slog.Int("variables", lp.Width()-1))
+	}
+	lp.control = control
+	defer func() { lp.control = nil }()
+	lp.solve()
+	result := lp.result(control, time.Since(start))
+	if lp.logger != nil {
+		lp.logger.Info("simplex finished",
+			slog.String("status", result.Status.String()),
+			slog.Int("iterations", result.Iterations),
+			slog.Float64("objective", result.ObjectiveValue),
+			slog.Duration("time", result.Time))
+	}
+	return result
+}
+
+// counts an iteration of the simplex algorithm; returns false if a limit stops
+// the algorithm before it
+func (l *solveControl) next() bool {
+	if l == nil {
+		return true
+	}
+	if l.reached {
+		return false
+	}
+	switch err := l.ctx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		l.status = TIMELIMIT
+	case err != nil:
+		l.status = CANCELED
+	case l.maxIterations > 0 && l.iterations >= l.maxIterations:
+		l.status = ITERATIONLIMIT
+	case !l.deadline.IsZero() && !time.Now().Before(l.deadline):
+		l.status = TIMELIMIT
+	default:
+		l.iterations++
+		return true
+	}
+	l.reached = true
+	return false
+}
+
+// stops the simplex algorithm with the status unless a limit already did
+func (l *solveControl) stop(status Status) {
+	if l != nil && !l.reached {
+		l.reached = true
+		l.status = status
+	}
+}
+
+// true if a limit stopped the simplex algorithm
+func (l *solveControl) stopped() bool {
+	return l != nil && l.reached
+}
+
+func (l *solveControl) setPhase(phase int) {
+	if l != nil {
+		l.phase = phase
+	}
+}
+
+// true if there is a callback to report iterations to
+func (l *solveControl) reporting() bool {
+	return l != nil && l.callback != nil
+}
+
+// passes the progress of the current iteration to the callback, which may stop
+// the simplex algorithm
+func (l *solveControl) report(p Progress) {
+	p.Phase = l.phase
+	p.Iteration = l.iterations
+	if !l.callback(p) && !l.reached {
+		l.reached = true
+		l.status = INTERRUPTED
+	}
+}
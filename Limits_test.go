@@ -0,0 +1,89 @@
+package sago
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// Beale's example, which cycles with the Dantzig pivot rule
+func bealeLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 0, 0, 0, .75, -20, .5, -6)
+	lp.AddConstraintEq(0, 1, 0, 0, .25, -8, -1, 9)
+	lp.AddConstraintEq(0, 0, 1, 0, .5, -12, -.5, 3)
+	lp.AddConstraintEq(1, 0, 0, 1, 0, 0, 1, 0)
+	lp.SetPivotRule(Dantzig{})
+	return lp
+}
+
+func TestLP_OptimizeContext(t *testing.T) {
+	ctx := context.Background()
+	lp := sensitivityLP()
+	assert.Equal(t, OPTIMAL, lp.OptimizeContext(ctx, SolveOptions{IterationLimit: 100}).Status)
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+
+	lp = bealeLP()
+	result := lp.OptimizeContext(ctx, SolveOptions{IterationLimit: 50})
+	assert.Equal(t, ITERATIONLIMIT, result.Status)
+	assert.True(t, result.Status.Limit())
+	assert.Equal(t, 50, result.Iterations)
+	assert.False(t, lp.Solved())
+	assert.Len(t, lp.Basis(), 3)
+	// the last basis is feasible
+	assert.Len(t, result.Solution, 7)
+	assert.True(t, lp.satisfies(result.Solution))
+	_, err := lp.Solution()
+	assert.IsType(t, SolutionUnavailableError{}, err)
+
+	// the LP can be optimized again without the limit
+	lp.SetPivotRule(Bland{})
+	lp.Optimize()
+	assert.True(t, lp.Optimal())
+	assert.InDelta(t, 1.25, lp.ObjectiveValue(), EPSILON)
+
+	lp = bealeLP()
+	start := time.Now()
+	assert.Equal(t, TIMELIMIT, lp.OptimizeContext(ctx, SolveOptions{TimeLimit: 20 * time.Millisecond}).Status)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelTimeout()
+	assert.Equal(t, TIMELIMIT, bealeLP().OptimizeContext(timeout, SolveOptions{}).Status)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	lp = sensitivityLP()
+	assert.Equal(t, CANCELED, lp.OptimizeContext(canceled, SolveOptions{}).Status)
+	assert.False(t, lp.Solved())
+}
+
+func TestLP_OptimizeContextEngines(t *testing.T) {
+	ctx := context.Background()
+
+	lp := sensitivityLP()
+	lp.SetEngine(REVISED)
+	assert.Equal(t, ITERATIONLIMIT, lp.OptimizeContext(ctx, SolveOptions{IterationLimit: 1}).Status)
+	assert.False(t, lp.Solved())
+	assert.Equal(t, OPTIMAL, lp.OptimizeContext(ctx, SolveOptions{}).Status)
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+
+	// the dual simplex algorithm of a warm start
+	lp = sensitivityLP()
+	lp.Optimize()
+	lp.AddConstraintLeq(7, 1, 1)
+	lp.AddConstraintGeq(5, 1, 1)
+	assert.Equal(t, ITERATIONLIMIT, lp.OptimizeContext(ctx, SolveOptions{IterationLimit: 1}).Status)
+	assert.False(t, lp.Solved())
+	assert.Equal(t, INFEASIBLE, lp.OptimizeContext(ctx, SolveOptions{}).Status)
+	assert.False(t, lp.Feasible())
+
+	// bounded variables
+	lp = sensitivityLP()
+	lp.SetBounds(0, 1, 3)
+	assert.Equal(t, ITERATIONLIMIT, lp.OptimizeContext(ctx, SolveOptions{IterationLimit: 1}).Status)
+	assert.Equal(t, OPTIMAL, lp.OptimizeContext(ctx, SolveOptions{}).Status)
+	assert.InDelta(t, 36, lp.ObjectiveValue(), EPSILON)
+}
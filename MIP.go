@@ -0,0 +1,246 @@
+package sago
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	//BESTBOUND explores the open node with the best LP relaxation value first
+	BESTBOUND = iota
+	//DEPTHFIRST explores the most recently created node first
+	DEPTHFIRST
+)
+
+// distance from the nearest integer below which a value is integral
+const integralityTolerance = 1e-6
+
+//MIPOptions configures branch-and-bound
+type MIPOptions struct {
+	NodeSelection int           // BESTBOUND or DEPTHFIRST
+	Gap           float64       // relative gap between incumbent and bound at which to stop
+	NodeLimit     int           // maximum number of LP relaxations to solve; 0 for no limit
+	TimeLimit     time.Duration // 0 for no limit
+}
+
+//MIPResult is the outcome of branch-and-bound
+type MIPResult struct {
+	Solution       []float64 // best integer solution found, or nil
+	ObjectiveValue float64   // objective value of Solution
+	Bound          float64   // bound on the objective value of any integer solution
+	Gap            float64   // relative gap between ObjectiveValue and Bound
+	Nodes          int       // number of LP relaxations solved
+	Optimal        bool      // true if Solution is optimal within the gap
+}
+
+// a solved LP relaxation in the branch-and-bound tree; its bound is the
+// objective value of the relaxation multiplied by the sense, so that larger
+// bounds are better whether maximizing or minimizing
+type mipNode struct {
+	lp    *LP
+	bound float64
+}
+
+type mipQueue struct {
+	depthFirst bool
+	nodes      []*mipNode
+}
+
+//SetInteger marks the variables at the given indices as integer
+func (lp *LP) SetInteger(cols ...int) {
+	if lp.integers == nil {
+		lp.integers = map[int]bool{}
+	}
+	for _, c := range cols {
+		lp.integers[c] = true
+	}
+}
+
+//SetBinary marks the variables at the given indices as integer with 0 <= x <= 1
+func (lp *LP) SetBinary(cols ...int) {
+	lp.SetInteger(cols...)
+	if lp.binaries == nil {
+		lp.binaries = map[int]bool{}
+	}
+	for _, c := range cols {
+		lp.binaries[c] = true
+	}
+}
+
+//SetContinuous removes the integer and binary marks of the variables at the given indices
+func (lp *LP) SetContinuous(cols ...int) {
+	for _, c := range cols {
+		delete(lp.integers, c)
+		delete(lp.binaries, c)
+	}
+}
+
+//IsInteger returns true if the variable at index col is marked as integer or binary
+func (lp *LP) IsInteger(col int) bool {
+	return lp.integers[col]
+}
+
+//GetMIPOptions returns the options used by OptimizeMIP
+func (lp *LP) GetMIPOptions() MIPOptions {
+	return lp.mipOptions
+}
+
+//SetMIPOptions sets the options used by OptimizeMIP
+func (lp *LP) SetMIPOptions(options MIPOptions) {
+	lp.mipOptions = options
+}
+
+//OptimizeMIP runs branch-and-bound on copies of the LP, branching on integer
+//variables with fractional values in the LP relaxation. The LP itself is not
+//modified. Returns an error if no integer solution was found.
+func (lp *LP) OptimizeMIP() (*MIPResult, error) {
+	start := time.Now()
+	options := lp.mipOptions
+	n := lp.Width() - 1
+
+	root := lp.Copy()
+	for _, c := range sortedKeys(lp.binaries) {
+		lower, upper := root.Bounds(c)
+		root.SetBounds(c, math.Max(lower, 0), math.Min(upper, 1))
+	}
+	root.solve()
+	s := lp.sense()
+	result := &MIPResult{Nodes: 1, ObjectiveValue: s * math.Inf(-1), Bound: s * math.Inf(-1)}
+	if !root.Feasible() {
+		return result, root.infeasibleError()
+	}
+	if !root.Bounded() {
+		e := root.unboundedError()
+		e.s = "LP relaxation is unbounded"
+		return result, e
+	}
+
+	// the objective value of the incumbent multiplied by the sense, as the bounds,
+	// which tell apart objective values more than the Zero tolerance apart
+	incumbent := math.Inf(-1)
+	tol := lp.GetTolerances().Zero
+	open := &mipQueue{depthFirst: options.NodeSelection == DEPTHFIRST}
+	open.push(&mipNode{lp: root, bound: s * root.ObjectiveValue()})
+	for len(open.nodes) > 0 {
+		if result.Solution != nil && relativeGap(incumbent, open.bound(), tol) <= options.Gap {
+			break
+		}
+		if options.NodeLimit > 0 && result.Nodes >= options.NodeLimit ||
+			options.TimeLimit > 0 && time.Since(start) >= options.TimeLimit {
+			break
+		}
+
+		node := open.pop()
+		if Fle(node.bound, incumbent, tol) {
+			continue
+		}
+		sol, _ := node.lp.Solution()
+		col := lp.branchingColumn(sol)
+		if col < 0 {
+			result.Solution = sol[:n]
+			incumbent = node.bound
+			continue
+		}
+
+		// x_col <= floor(value) and x_col >= ceil(value)
+		down := node.lp.Copy()
+		down.AddConstraintGeq(math.Floor(sol[col]), unitVector(col)...)
+		up := node.lp.Copy()
+		up.AddConstraintLeq(math.Ceil(sol[col]), unitVector(col)...)
+		for _, child := range []*LP{down, up} {
+			child.solve()
+			result.Nodes++
+			if _, err := child.Solution(); err == nil && Fgt(s*child.ObjectiveValue(), incumbent, tol) {
+				open.push(&mipNode{lp: child, bound: s * child.ObjectiveValue()})
+			}
+		}
+	}
+
+	bound := math.Max(incumbent, open.bound())
+	result.ObjectiveValue, result.Bound = s*incumbent, s*bound
+	result.Gap = relativeGap(incumbent, bound, tol)
+	result.Optimal = result.Solution != nil && result.Gap <= options.Gap
+	if result.Solution == nil {
+		if len(open.nodes) > 0 {
+			return result, SolutionUnavailableError{"no integer solution found before reaching a limit"}
+		}
+		return result, NoSolutionError{"LP has no integer solution"}
+	}
+	return result, nil
+}
+
+// returns the integer variable whose value is farthest from an integer, or -1
+// if all integer variables are integral
+func (lp *LP) branchingColumn(sol []float64) int {
+	col := -1
+	farthest := integralityTolerance
+	for _, c := range sortedKeys(lp.integers) {
+		if c >= len(sol) {
+			continue
+		}
+		if d := math.Abs(sol[c] - math.Round(sol[c])); d > farthest {
+			col, farthest = c, d
+		}
+	}
+	return col
+}
+
+func (q *mipQueue) push(node *mipNode) {
+	q.nodes = append(q.nodes, node)
+}
+
+// removes the most recent node when searching depth first and the node with the
+// best bound otherwise
+func (q *mipQueue) pop() *mipNode {
+	i := len(q.nodes) - 1
+	if !q.depthFirst {
+		for j, node := range q.nodes {
+			if node.bound > q.nodes[i].bound {
+				i = j
+			}
+		}
+	}
+	node := q.nodes[i]
+	q.nodes = append(q.nodes[:i], q.nodes[i+1:]...)
+	return node
+}
+
+// best bound among the open nodes
+func (q *mipQueue) bound() float64 {
+	bound := math.Inf(-1)
+	for _, node := range q.nodes {
+		bound = math.Max(bound, node.bound)
+	}
+	return bound
+}
+
+// the gap between the incumbent and the bound relative to the incumbent, or to
+// tolerance if the incumbent is smaller
+func relativeGap(incumbent, bound, tolerance float64) float64 {
+	if math.IsInf(incumbent, -1) {
+		return math.Inf(1)
+	}
+	if math.IsInf(bound, -1) {
+		return 0
+	}
+	return math.Abs(bound-incumbent) / math.Max(math.Abs(incumbent), tolerance)
+}
+
+// coefficients (0, ..., 0, 1) selecting the variable at index col
+func unitVector(col int) []float64 {
+	coefs := make([]float64, col+1)
+	coefs[col] = 1
+	return coefs
+}
+
+func sortedKeys(m map[int]bool) []int {
+	var keys []int
+	for k, v := range m {
+		if v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Ints(keys)
+	return keys
+}
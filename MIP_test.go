@@ -0,0 +1,121 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// max x1 + x2 st. -x1 + x2 <= 1, 3x1 + 2x2 <= 12, 2x1 + 3x2 <= 12
+func integerLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, -1, 1)
+	lp.AddConstraintGeq(12, 3, 2)
+	lp.AddConstraintGeq(12, 2, 3)
+	lp.SetInteger(0, 1)
+	return lp
+}
+
+func TestLP_SetInteger(t *testing.T) {
+	lp := NewLP()
+	lp.SetInteger(0, 2)
+	lp.SetBinary(3)
+	assert.True(t, lp.IsInteger(0))
+	assert.False(t, lp.IsInteger(1))
+	assert.True(t, lp.IsInteger(3))
+	lp.SetContinuous(0, 3)
+	assert.False(t, lp.IsInteger(0))
+	assert.False(t, lp.IsInteger(3))
+	assert.Equal(t, []int{2}, sortedKeys(lp.integers))
+
+	c := lp.Copy()
+	c.SetInteger(5)
+	assert.False(t, lp.IsInteger(5))
+}
+
+func TestLP_OptimizeMIP(t *testing.T) {
+	for _, selection := range []int{BESTBOUND, DEPTHFIRST} {
+		lp := integerLP()
+		lp.SetMIPOptions(MIPOptions{NodeSelection: selection})
+		result, err := lp.OptimizeMIP()
+		assert.NoError(t, err)
+		assert.True(t, result.Optimal)
+		assert.InDelta(t, 4, result.ObjectiveValue, 1e-9)
+		assert.InDelta(t, 4, result.Bound, 1e-9)
+		assert.Len(t, result.Solution, 5)
+		for _, x := range result.Solution[:2] {
+			assert.InDelta(t, x, float64(int(x+.5)), 1e-9)
+		}
+		assert.Greater(t, result.Nodes, 1)
+
+		// the LP itself is not modified
+		assert.False(t, lp.Solved())
+		assert.Equal(t, 3, lp.NumConstraints())
+	}
+}
+
+func TestLP_OptimizeMIPBinary(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 8, 11, 6, 4)
+	lp.AddConstraintGeq(14, 5, 7, 4, 3)
+	lp.SetBinary(0, 1, 2, 3)
+	result, err := lp.OptimizeMIP()
+	assert.NoError(t, err)
+	assert.InDelta(t, 21, result.ObjectiveValue, 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 1, 1, 1, 0}, result.Solution, 1e-9)
+}
+
+func TestLP_OptimizeMIPMinimize(t *testing.T) {
+	// min 3x1 + 2x2 st. 2x1 + 2x2 >= 5, whose LP relaxation gives 5
+	for _, selection := range []int{BESTBOUND, DEPTHFIRST} {
+		lp := NewLP()
+		lp.SetObjectiveFunction(MINIMIZE, 0, 3, 2)
+		lp.AddConstraintLeq(5, 2, 2)
+		lp.SetInteger(0, 1)
+		lp.SetMIPOptions(MIPOptions{NodeSelection: selection})
+		result, err := lp.OptimizeMIP()
+		assert.NoError(t, err)
+		assert.True(t, result.Optimal)
+		assert.InDelta(t, 6, result.ObjectiveValue, 1e-9)
+		assert.InDelta(t, 6, result.Bound, 1e-9)
+		assert.InDeltaSlice(t, []float64{0, 3}, result.Solution[:2], 1e-9)
+
+		lp.SetMIPOptions(MIPOptions{NodeLimit: 1})
+		result, err = lp.OptimizeMIP()
+		assert.IsType(t, SolutionUnavailableError{}, err)
+		assert.InDelta(t, 5, result.Bound, 1e-9)
+	}
+}
+
+func TestLP_OptimizeMIPLimits(t *testing.T) {
+	lp := integerLP()
+	lp.SetMIPOptions(MIPOptions{NodeLimit: 1})
+	result, err := lp.OptimizeMIP()
+	assert.IsType(t, SolutionUnavailableError{}, err)
+	assert.False(t, result.Optimal)
+	assert.InDelta(t, 4.8, result.Bound, 1e-9)
+
+	// any integer solution is within a gap of 100%
+	lp = integerLP()
+	lp.SetMIPOptions(MIPOptions{NodeSelection: DEPTHFIRST, Gap: 1})
+	result, err = lp.OptimizeMIP()
+	assert.NoError(t, err)
+	assert.True(t, result.Optimal)
+	assert.LessOrEqual(t, result.Gap, float64(1))
+}
+
+func TestLP_OptimizeMIPInfeasible(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintEq(1, 2)
+	lp.SetInteger(0)
+	_, err := lp.OptimizeMIP()
+	assert.IsType(t, NoSolutionError{}, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintLeq(1, 1)
+	lp.SetInteger(0)
+	_, err = lp.OptimizeMIP()
+	assert.IsType(t, UnboundedError{}, err)
+}
@@ -0,0 +1,518 @@
+package sago
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// a row of an MPS file
+type mpsRow struct {
+	name   string
+	kind   byte // 'N', 'L', 'G' or 'E'
+	coefs  map[int]float64
+	rhs    float64
+	ranged bool
+	rng    float64
+}
+
+// state of ReadMPS
+type mpsReader struct {
+	line      int
+	section   string
+	sense     int
+	objective int // index of the objective row in rows, or -1
+	rows      []*mpsRow
+	rowIndex  map[string]int
+	columns   []string
+	colIndex  map[string]int
+	integer   map[int]bool
+	binary    map[int]bool
+	lower     map[int]float64
+	upper     map[int]float64
+	marked    bool // between INTORG and INTEND markers
+}
+
+//ReadMPS reads an LP in free MPS format, where the fields of a line are
+//separated by spaces. Files in fixed MPS format are read the same way, not by
+//column position, so their names must not contain spaces either. The first N
+//row is the objective function, to be minimized unless an OBJSENSE section says
+//otherwise; the remaining rows become constraints in the order they are
+//listed; ranged rows are added with AddConstraintRange. Variables are numbered
+//in the order they first appear in the COLUMNS section; their bounds are set
+//with SetBounds. As in other MPS readers, an UP or UI bound below 0 on a
+//variable without a LO or LI bound before it also sets its lower bound to
+//-infinity rather than leaving it at 0, which would make the variable
+//infeasible; a later LO or LI bound still replaces it. Integer variables are
+//marked as such.
+//Returns an InvalidInputError with the line number if the input cannot be
+//parsed.
+func ReadMPS(r io.Reader) (*LP, error) {
+	m := &mpsReader{
+		sense:     MINIMIZE,
+		objective: -1,
+		rowIndex:  map[string]int{},
+		colIndex:  map[string]int{},
+		integer:   map[int]bool{},
+		binary:    map[int]bool{},
+		lower:     map[int]float64{},
+		upper:     map[int]float64{},
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m.line++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "*") {
+			continue
+		}
+		fields := strings.Fields(line)
+		var err error
+		if line[0] != ' ' && line[0] != '\t' {
+			err = m.readSection(fields)
+		} else {
+			err = m.readEntry(fields)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.section == "ENDATA" {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m.lp()
+}
+
+func (m *mpsReader) error(format string, a ...interface{}) error {
+	return InvalidInputError{s: fmt.Sprintf(format, a...), Line: m.line}
+}
+
+func (m *mpsReader) readSection(fields []string) error {
+	switch fields[0] {
+	case "NAME", "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS", "ENDATA":
+	case "OBJSENSE":
+		if len(fields) > 1 {
+			return m.readSense(fields[1])
+		}
+	default:
+		return m.error("unknown section %q", fields[0])
+	}
+	m.section = fields[0]
+	return nil
+}
+
+func (m *mpsReader) readSense(sense string) error {
+	switch sense {
+	case "MAX", "MAXIMIZE":
+		m.sense = MAXIMIZE
+	case "MIN", "MINIMIZE":
+		m.sense = MINIMIZE
+	default:
+		return m.error("unknown objective sense %q", sense)
+	}
+	return nil
+}
+
+func (m *mpsReader) readEntry(fields []string) error {
+	switch m.section {
+	case "OBJSENSE":
+		return m.readSense(fields[0])
+	case "ROWS":
+		return m.readRow(fields)
+	case "COLUMNS":
+		return m.readColumn(fields)
+	case "RHS", "RANGES":
+		return m.readValues(fields)
+	case "BOUNDS":
+		return m.readBound(fields)
+	}
+	return m.error("unexpected entry in section %q", m.section)
+}
+
+func (m *mpsReader) readRow(fields []string) error {
+	if len(fields) != 2 {
+		return m.error("expected row type and name")
+	}
+	kind := strings.ToUpper(fields[0])
+	if kind != "N" && kind != "L" && kind != "G" && kind != "E" {
+		return m.error("unknown row type %q", fields[0])
+	}
+	if _, ok := m.rowIndex[fields[1]]; ok {
+		return m.error("duplicate row %q", fields[1])
+	}
+	if kind == "N" && m.objective < 0 {
+		m.objective = len(m.rows)
+	}
+	m.rowIndex[fields[1]] = len(m.rows)
+	m.rows = append(m.rows, &mpsRow{name: fields[1], kind: kind[0], coefs: map[int]float64{}})
+	return nil
+}
+
+func (m *mpsReader) readColumn(fields []string) error {
+	if len(fields) == 3 && strings.Trim(fields[1], "'") == "MARKER" {
+		switch strings.Trim(fields[2], "'") {
+		case "INTORG":
+			m.marked = true
+		case "INTEND":
+			m.marked = false
+		default:
+			return m.error("unknown marker %q", fields[2])
+		}
+		return nil
+	}
+	if len(fields) != 3 && len(fields) != 5 {
+		return m.error("expected column name followed by row and value pairs")
+	}
+	col, ok := m.colIndex[fields[0]]
+	if !ok {
+		col = len(m.columns)
+		m.colIndex[fields[0]] = col
+		m.columns = append(m.columns, fields[0])
+	}
+	if m.marked {
+		m.integer[col] = true
+	}
+	for i := 1; i < len(fields); i += 2 {
+		row, err := m.row(fields[i])
+		if err != nil {
+			return err
+		}
+		v, err := m.number(fields[i+1])
+		if err != nil {
+			return err
+		}
+		row.coefs[col] += v
+	}
+	return nil
+}
+
+// reads an entry of the RHS or RANGES section; the name of the set is optional
+func (m *mpsReader) readValues(fields []string) error {
+	if len(fields)%2 == 1 {
+		fields = fields[1:]
+	}
+	if len(fields) != 2 && len(fields) != 4 {
+		return m.error("expected row and value pairs")
+	}
+	for i := 0; i < len(fields); i += 2 {
+		row, err := m.row(fields[i])
+		if err != nil {
+			return err
+		}
+		v, err := m.number(fields[i+1])
+		if err != nil {
+			return err
+		}
+		if m.section == "RHS" {
+			row.rhs = v
+		} else {
+			row.ranged, row.rng = true, v
+		}
+	}
+	return nil
+}
+
+// reads an entry of the BOUNDS section; the name of the set is optional
+func (m *mpsReader) readBound(fields []string) error {
+	kind := strings.ToUpper(fields[0])
+	valued := true
+	switch kind {
+	case "UP", "LO", "FX", "LI", "UI":
+	case "FR", "MI", "PL", "BV":
+		valued = false
+	default:
+		return m.error("unknown bound type %q", fields[0])
+	}
+	args := fields[1:]
+	if valued && len(args) == 3 || !valued && len(args) >= 2 {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return m.error("expected column name")
+	}
+	col, ok := m.colIndex[args[0]]
+	if !ok {
+		return m.error("unknown column %q", args[0])
+	}
+	v := 0.0
+	if valued {
+		if len(args) != 2 {
+			return m.error("expected column name and value")
+		}
+		var err error
+		if v, err = m.number(args[1]); err != nil {
+			return err
+		}
+	}
+
+	switch kind {
+	case "UP", "UI":
+		if _, ok := m.lower[col]; !ok && v < 0 {
+			m.lower[col] = math.Inf(-1)
+		}
+		m.upper[col] = v
+	case "LO", "LI":
+		m.lower[col] = v
+	case "FX":
+		m.lower[col], m.upper[col] = v, v
+	case "FR":
+		m.lower[col], m.upper[col] = math.Inf(-1), math.Inf(1)
+	case "MI":
+		m.lower[col] = math.Inf(-1)
+	case "PL":
+		m.upper[col] = math.Inf(1)
+	case "BV":
+		m.binary[col] = true
+	}
+	if kind == "UI" || kind == "LI" {
+		m.integer[col] = true
+	}
+	return nil
+}
+
+func (m *mpsReader) row(name string) (*mpsRow, error) {
+	i, ok := m.rowIndex[name]
+	if !ok {
+		return nil, m.error("unknown row %q", name)
+	}
+	return m.rows[i], nil
+}
+
+func (m *mpsReader) number(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, m.error("invalid number %q", s)
+	}
+	return v, nil
+}
+
+// builds the LP from the sections read
+func (m *mpsReader) lp() (*LP, error) {
+	if m.section != "ENDATA" {
+		return nil, m.error("missing ENDATA")
+	}
+	n := len(m.columns)
+	lp := NewLP()
+	c := make([]float64, n)
+	z := 0.0
+	if m.objective >= 0 {
+		obj := m.rows[m.objective]
+		for j, v := range obj.coefs {
+			c[j] = v
+		}
+		// the right hand side of the objective function is its negated constant
+		z = -obj.rhs
+	}
+	lp.SetObjectiveFunction(m.sense, z, c...)
+
+	for _, row := range m.rows {
+		if row.kind == 'N' {
+			continue
+		}
+		a := make([]float64, n)
+		for j, v := range row.coefs {
+			a[j] = v
+		}
+		if !row.ranged {
+			switch row.kind {
+			case 'L':
+				lp.AddConstraintGeq(row.rhs, a...)
+			case 'G':
+				lp.AddConstraintLeq(row.rhs, a...)
+			case 'E':
+				lp.AddConstraintEq(row.rhs, a...)
+			}
+			continue
+		}
+		lo, hi := row.rhs, row.rhs
+		switch {
+		case row.kind == 'L':
+			lo -= math.Abs(row.rng)
+		case row.kind == 'G':
+			hi += math.Abs(row.rng)
+		case row.rng < 0:
+			lo += row.rng
+		default:
+			hi += row.rng
+		}
+		lp.AddConstraintRange(lo, hi, a...)
+	}
+
+	for col := range m.columns {
+		lo, up := m.lower[col], math.Inf(1)
+		if u, ok := m.upper[col]; ok {
+			up = u
+		}
+		if lo > up {
+			return nil, InvalidInputError{s: fmt.Sprintf("lower bound of %q exceeds its upper bound", m.columns[col])}
+		}
+		if lo != 0 || !math.IsInf(up, 1) {
+			lp.SetBounds(col, lo, up)
+		}
+	}
+	lp.SetInteger(sortedKeys(m.integer)...)
+	lp.SetBinary(sortedKeys(m.binary)...)
+	return lp, nil
+}
+
+//WriteMPS writes the LP as built in free MPS format. Variables are named x1,
+//x2, ... and constraints c1, c2, ...; slack variables are left out. Integer
+//variables are written between markers and binary variables as BV bounds.
+//Ranges are written as L rows with an entry in the RANGES section and bounds set
+//with SetBounds to the BOUNDS section.
+func (lp *LP) WriteMPS(w io.Writer) error {
+	lp.densify()
+	rows := lp.tableau
+	if lp.dirty {
+		rows = lp.original
+	}
+	slack := map[int]bool{}
+	for i := range rows[1:] {
+		if s := lp.rowInfo(i).slack; s >= 0 {
+			slack[s] = true
+		}
+	}
+	var columns []int
+	for j := 0; j < lp.Width()-1; j++ {
+		if !slack[j] {
+			columns = append(columns, j)
+		}
+	}
+	name := map[int]string{}
+	for k, j := range columns {
+		name[j] = fmt.Sprintf("x%d", k+1)
+	}
+	// the coefficients and right hand side of the i-th constraint as added
+	value := func(i, j int) float64 {
+		if j >= len(rows[i]) {
+			return 0
+		}
+		if i == 0 {
+			return rows[0][j]
+		}
+		return rows[i][j] * lp.rowInfo(i-1).sign
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("NAME\n")
+	if lp.objective == MAXIMIZE {
+		b.WriteString("OBJSENSE\n    MAX\n")
+	}
+	b.WriteString("ROWS\n N  obj\n")
+	for i := range rows[1:] {
+		kind := "E"
+		switch lp.rowInfo(i).kind {
+		case constraintGeq, constraintRange:
+			kind = "L"
+		case constraintLeq:
+			kind = "G"
+		}
+		fmt.Fprintf(b, " %s  c%d\n", kind, i+1)
+	}
+
+	b.WriteString("COLUMNS\n")
+	marked := false
+	for _, j := range columns {
+		integer := lp.integers[j] && !lp.binaries[j]
+		if integer != marked {
+			marker := "INTORG"
+			if marked {
+				marker = "INTEND"
+			}
+			fmt.Fprintf(b, "    MARKER    'MARKER'  '%s'\n", marker)
+			marked = integer
+		}
+		if allZero(rows, j+1) {
+			// keep the column in the LP
+			fmt.Fprintf(b, "    %-8s  %-8s  0\n", name[j], "obj")
+		}
+		for i := range rows {
+			if v := value(i, j+1); v != 0 {
+				row := "obj"
+				if i > 0 {
+					row = fmt.Sprintf("c%d", i)
+				}
+				fmt.Fprintf(b, "    %-8s  %-8s  %s\n", name[j], row, formatMPS(v))
+			}
+		}
+	}
+	if marked {
+		b.WriteString("    MARKER    'MARKER'  'INTEND'\n")
+	}
+
+	b.WriteString("RHS\n")
+	if z := value(0, 0); z != 0 {
+		fmt.Fprintf(b, "    RHS       obj       %s\n", formatMPS(-z))
+	}
+	for i := 1; i < len(rows); i++ {
+		if v := value(i, 0); v != 0 {
+			fmt.Fprintf(b, "    RHS       %-8s  %s\n", fmt.Sprintf("c%d", i), formatMPS(v))
+		}
+	}
+
+	var ranges []string
+	for i := range rows[1:] {
+		if info := lp.rowInfo(i); info.kind == constraintRange {
+			_, upper := lp.Bounds(info.slack)
+			ranges = append(ranges, fmt.Sprintf("    RNG       %-8s  %s", fmt.Sprintf("c%d", i+1), formatMPS(upper)))
+		}
+	}
+	if len(ranges) > 0 {
+		b.WriteString("RANGES\n")
+		for _, line := range ranges {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	var bounds []string
+	for _, j := range columns {
+		if lp.binaries[j] {
+			bounds = append(bounds, fmt.Sprintf(" BV BND       %s", name[j]))
+			continue
+		}
+		lower, upper := lp.Bounds(j)
+		switch {
+		case lower == upper:
+			bounds = append(bounds, fmt.Sprintf(" FX BND       %-8s  %s", name[j], formatMPS(lower)))
+			continue
+		case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+			bounds = append(bounds, fmt.Sprintf(" FR BND       %s", name[j]))
+			continue
+		case math.IsInf(lower, -1):
+			bounds = append(bounds, fmt.Sprintf(" MI BND       %s", name[j]))
+		case lower != 0:
+			bounds = append(bounds, fmt.Sprintf(" LO BND       %-8s  %s", name[j], formatMPS(lower)))
+		}
+		if !math.IsInf(upper, 1) {
+			bounds = append(bounds, fmt.Sprintf(" UP BND       %-8s  %s", name[j], formatMPS(upper)))
+		}
+	}
+	if len(bounds) > 0 {
+		b.WriteString("BOUNDS\n")
+		for _, line := range bounds {
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString("ENDATA\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// true if column j is 0 in every row
+func allZero(rows tableau, j int) bool {
+	for _, row := range rows {
+		if j < len(row) && row[j] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func formatMPS(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,182 @@
+package sago
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readMPS(t *testing.T, testName string) *LP {
+	f, err := os.Open("Tests/" + testName)
+	assert.NoError(t, err)
+	defer f.Close()
+	lp, err := ReadMPS(f)
+	assert.NoError(t, err)
+	return lp
+}
+
+func TestReadMPS(t *testing.T) {
+	lp := readMPS(t, "MPS_example.mps")
+	expected := tableau{
+		{2, 3, 5, 1, 0, 0, 0},
+		{4, 1, 0, 0, 1, 0, 0},
+		{12, 0, 2, 0, 0, 1, 0},
+		{18, 3, 2, 0, 0, 0, 1},
+	}
+	assert.Equal(t, expected, lp.tableau)
+	assert.Equal(t, MAXIMIZE, lp.GetObjective())
+	lower, upper := lp.Bounds(lp.SlackColumn(2))
+	assert.Equal(t, []float64{0, 6}, []float64{lower, upper})
+	lower, upper = lp.Bounds(0)
+	assert.Equal(t, []float64{0, 10}, []float64{lower, upper})
+	assert.False(t, lp.IsInteger(0))
+	assert.True(t, lp.IsInteger(1))
+	assert.True(t, lp.IsInteger(2))
+
+	result, err := lp.OptimizeMIP()
+	assert.NoError(t, err)
+	assert.InDelta(t, 39, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, []float64{2, 6, 1}, result.Solution[:3], EPSILON)
+}
+
+func TestReadMPS_Minimize(t *testing.T) {
+	lp := readMPS(t, "MPS_min.mps")
+	assert.Equal(t, MINIMIZE, lp.GetObjective())
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 10, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, []float64{3, 1}, result.Solution[:2], EPSILON)
+}
+
+func TestReadMPS_Free(t *testing.T) {
+	mps := `NAME
+ROWS
+ N obj
+ E c1
+ G c2
+COLUMNS
+ x obj 1 c1 1
+ y obj 2 c1 1
+ y c2 1
+ z c2 1
+ w c2 1
+ v c2 1
+RHS
+ c1 5 c2 1
+BOUNDS
+ FX BND x 2
+ MI BND y
+ UP BND y 4
+ UP z -1
+ FR w
+ LO v -3
+ UP v -1
+ENDATA
+`
+	lp, err := ReadMPS(strings.NewReader(mps))
+	assert.NoError(t, err)
+	expected := tableau{
+		{0, 1, 2, 0, 0, 0, 0},
+		{5, 1, 1, 0, 0, 0, 0},
+		{1, 0, 1, 1, 1, 1, -1},
+	}
+	assert.Equal(t, expected, lp.tableau)
+	assert.Equal(t, MINIMIZE, lp.GetObjective())
+	// an upper bound below 0 makes z unbounded below, but not v, which has a
+	// lower bound
+	bounds := [][]float64{{2, 2}, {math.Inf(-1), 4}, {math.Inf(-1), -1}, {math.Inf(-1), math.Inf(1)}, {-3, -1}}
+	for j, expected := range bounds {
+		lower, upper := lp.Bounds(j)
+		assert.Equal(t, expected, []float64{lower, upper})
+	}
+
+	// bounds are written back
+	buf := &bytes.Buffer{}
+	assert.NoError(t, lp.WriteMPS(buf))
+	assert.Contains(t, buf.String(), "BOUNDS\n FX BND       x1        2\n MI BND       x2\n UP BND       x2        4\n")
+	read, err := ReadMPS(buf)
+	assert.NoError(t, err)
+	for j, expected := range bounds {
+		lower, upper := read.Bounds(j)
+		assert.Equal(t, expected, []float64{lower, upper})
+	}
+}
+
+func TestReadMPS_Errors(t *testing.T) {
+	tests := []struct {
+		mps  string
+		line int
+	}{
+		{"ROWS\n X c1\nENDATA\n", 2},
+		{"ROWS\n N obj\n L c1\n L c1\nENDATA\n", 4},
+		{"ROWS\n N obj\nCOLUMNS\n x c1 1\nENDATA\n", 4},
+		{"ROWS\n N obj\nCOLUMNS\n x obj one\nENDATA\n", 4},
+		{"ROWS\n N obj\nCOLUMNS\n x obj 1\nBOUNDS\n XX BND x\nENDATA\n", 6},
+		{"ROWS\n N obj\nCOLUMNS\n x obj 1\nBOUNDS\n UP BND y 1\nENDATA\n", 6},
+		{"ROWS\n N obj\nSOLUTION\nENDATA\n", 3},
+		{"ROWS\n N obj\n", 2},
+	}
+	for _, test := range tests {
+		_, err := ReadMPS(strings.NewReader(test.mps))
+		if assert.IsType(t, InvalidInputError{}, err, test.mps) {
+			assert.Equal(t, test.line, err.(InvalidInputError).Line, test.mps)
+		}
+	}
+}
+
+func TestLP_WriteMPS(t *testing.T) {
+	lp := sensitivityLP()
+	lp.AddConstraintLeq(1, 1, 1)
+	lp.SetInteger(1)
+	lp.Optimize()
+	buf := &bytes.Buffer{}
+	assert.NoError(t, lp.WriteMPS(buf))
+	expected := `NAME
+OBJSENSE
+    MAX
+ROWS
+ N  obj
+ L  c1
+ L  c2
+ L  c3
+ G  c4
+COLUMNS
+    x1        obj       3
+    x1        c1        1
+    x1        c3        3
+    x1        c4        1
+    MARKER    'MARKER'  'INTORG'
+    x2        obj       5
+    x2        c2        2
+    x2        c3        2
+    x2        c4        1
+    MARKER    'MARKER'  'INTEND'
+RHS
+    RHS       c1        4
+    RHS       c2        12
+    RHS       c3        18
+    RHS       c4        1
+ENDATA
+`
+	assert.Equal(t, expected, buf.String())
+
+	// reading the output back gives the LP as built
+	read, err := ReadMPS(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, lp.original, read.tableau)
+	assert.True(t, read.IsInteger(1))
+
+	lp = readMPS(t, "MPS_example.mps")
+	buf.Reset()
+	assert.NoError(t, lp.WriteMPS(buf))
+	assert.Contains(t, buf.String(), "RANGES\n    RNG       c3        6\n")
+	read, err = ReadMPS(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, lp.tableau, read.tableau)
+	assert.Equal(t, lp.integers, read.integers)
+	assert.Equal(t, lp.binaries, read.binaries)
+	assert.Equal(t, lp.bounds, read.bounds)
+}
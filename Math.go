@@ -1,5 +1,7 @@
 package sago
 
+import "math"
+
 //EPSILON is 1/2^32
 const EPSILON = float64(1) / float64(2<<32)
 
@@ -25,6 +27,56 @@ func Transpose(matrix [][]float64) [][]float64 {
 	return result
 }
 
+//Inverse returns the inverse of a square matrix using Gauss-Jordan elimination
+//with partial pivoting. The matrix is not modified.
+func Inverse(matrix [][]float64) ([][]float64, error) {
+	return invert(matrix, EPSILON)
+}
+
+// Inverse with the given smallest absolute value of a pivot
+func invert(matrix [][]float64, tolerance float64) ([][]float64, error) {
+	n := len(matrix)
+	work := make([][]float64, n)
+	for i := range matrix {
+		if len(matrix[i]) != n {
+			return nil, InvalidInputError{s: "matrix is not square"}
+		}
+		work[i] = make([]float64, 2*n)
+		copy(work[i], matrix[i])
+		work[i][n+i] = 1
+	}
+	for c := 0; c < n; c++ {
+		pivot := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(work[r][c]) > math.Abs(work[pivot][c]) {
+				pivot = r
+			}
+		}
+		if Feq(work[pivot][c], 0, tolerance) {
+			return nil, InvalidInputError{s: "matrix is singular"}
+		}
+		work[c], work[pivot] = work[pivot], work[c]
+		inverse := 1 / work[c][c]
+		for j := range work[c] {
+			work[c][j] *= inverse
+		}
+		for r := range work {
+			factor := work[r][c]
+			if r == c || factor == 0 {
+				continue
+			}
+			for j := range work[r] {
+				work[r][j] -= factor * work[c][j]
+			}
+		}
+	}
+	result := make([][]float64, n)
+	for i := range work {
+		result[i] = work[i][n:]
+	}
+	return result, nil
+}
+
 //ScalarVectorMultiply multiplies a vector by a scalar factor
 func ScalarVectorMultiply(factor float64, vector []float64) []float64 {
 	A := vector
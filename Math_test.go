@@ -49,3 +49,25 @@ func TestTranspose4(t *testing.T) {
 	}
 	assert.Equal(t, expected, Transpose(matrix))
 }
+
+func TestInverse(t *testing.T) {
+	matrix := [][]float64{
+		{0, 2, 0},
+		{1, 0, 0},
+		{3, 2, 1},
+	}
+	expected := [][]float64{
+		{0, 1, 0},
+		{.5, 0, 0},
+		{-1, -3, 1},
+	}
+	actual, err := Inverse(matrix)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, []float64{0, 2, 0}, matrix[0])
+
+	_, err = Inverse([][]float64{{1, 2}, {2, 4}})
+	assert.Error(t, err)
+	_, err = Inverse([][]float64{{1, 2}})
+	assert.Error(t, err)
+}
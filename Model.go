@@ -0,0 +1,429 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	relationEq = iota
+	relationLeq
+	relationGeq
+)
+
+//Model is a linear program built from named variables and linear expressions.
+//It is compiled into an LP when optimized.
+type Model struct {
+	constraints []Constraint
+	lp          *LP
+	objective   Expr
+	sense       int // MAXIMIZE or MINIMIZE
+	vars        []*Var
+}
+
+//Var is a decision variable of a Model
+type Var struct {
+	binary  bool
+	index   int
+	integer bool
+	lower   float64
+	model   *Model
+	name    string
+	upper   float64
+}
+
+//Expr is a linear expression c + a1 x1 + a2 x2 + ...
+type Expr struct {
+	constant float64
+	terms    map[*Var]float64
+}
+
+//Constraint is a relation between a linear expression and a constant
+type Constraint struct {
+	expr     Expr
+	name     string
+	relation int
+	rhs      float64
+}
+
+//Linear is anything that can be used as a linear expression, i.e. a *Var or an Expr
+type Linear interface {
+	toExpr() Expr
+}
+
+//NewModel creates a new, empty model
+func NewModel() *Model {
+	return &Model{
+		objective: Constant(0),
+		sense:     MAXIMIZE,
+	}
+}
+
+//NewVar adds a new variable to the model. Variables are nonnegative unless
+//their bounds are set otherwise. If name is empty, the variable is named x<n>
+//where n is its 1-based index.
+func (m *Model) NewVar(name string) *Var {
+	if name == "" {
+		name = fmt.Sprintf("x%d", len(m.vars)+1)
+	}
+	v := &Var{index: len(m.vars), model: m, name: name, upper: math.Inf(1)}
+	m.vars = append(m.vars, v)
+	return v
+}
+
+//Vars returns the variables of the model in the order they were created
+func (m *Model) Vars() []*Var {
+	return m.vars
+}
+
+//Var returns the variable with the given name, or nil if there is none
+func (m *Model) Var(name string) *Var {
+	for _, v := range m.vars {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+//Maximize sets the objective of the model to MAXIMIZE expr
+func (m *Model) Maximize(expr Linear) {
+	m.objective = expr.toExpr()
+	m.sense = MAXIMIZE
+}
+
+//Minimize sets the objective of the model to MINIMIZE expr
+func (m *Model) Minimize(expr Linear) {
+	m.objective = expr.toExpr()
+	m.sense = MINIMIZE
+}
+
+//Add adds a constraint to the model and returns its index. The index is also
+//the index of the constraint in the compiled LP.
+func (m *Model) Add(c Constraint) int {
+	m.constraints = append(m.constraints, c)
+	return len(m.constraints) - 1
+}
+
+//Constraints returns the constraints of the model in the order they were added
+func (m *Model) Constraints() []Constraint {
+	return m.constraints
+}
+
+// the name of the i-th constraint, or c<i+1> if it has none
+func (m *Model) constraintName(i int) string {
+	if name := m.constraints[i].name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("c%d", i+1)
+}
+
+//ComputeIIS returns the names of the constraints in an irreducible infeasible
+//subsystem of the model; constraints without a name are named c1, c2, ... by
+//their position. See LP.ComputeIIS.
+func (m *Model) ComputeIIS() ([]string, error) {
+	lp, err := m.LP()
+	if err != nil {
+		return nil, err
+	}
+	iis, err := lp.ComputeIIS()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(iis))
+	for k, i := range iis {
+		names[k] = m.constraintName(i)
+	}
+	return names, nil
+}
+
+//LP compiles the model into an LP. Column i of the LP holds the i-th variable;
+//slack variables for inequalities follow the model's variables. The bounds of
+//the variables are set with SetBounds, and integer and binary variables are
+//marked as such.
+func (m *Model) LP() (*LP, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	n := len(m.vars)
+	lp := NewLP()
+	lp.SetObjectiveFunction(m.sense, m.objective.constant, m.objective.coefficients(n)...)
+	for _, c := range m.constraints {
+		b := c.rhs - c.expr.constant
+		coefs := c.expr.coefficients(n)
+		switch c.relation {
+		case relationEq:
+			lp.AddConstraintEq(b, coefs...)
+		case relationLeq:
+			lp.AddConstraintGeq(b, coefs...)
+		case relationGeq:
+			lp.AddConstraintLeq(b, coefs...)
+		}
+	}
+	for _, v := range m.vars {
+		if v.binary {
+			lp.SetBinary(v.index)
+			continue
+		}
+		if v.integer {
+			lp.SetInteger(v.index)
+		}
+		if v.lower != 0 || !math.IsInf(v.upper, 1) {
+			lp.SetBounds(v.index, v.lower, v.upper)
+		}
+	}
+	return lp, nil
+}
+
+//Optimize compiles the model and executes the simplex algorithm on it
+func (m *Model) Optimize() error {
+	lp, err := m.LP()
+	if err != nil {
+		return err
+	}
+	lp.Optimize()
+	m.lp = lp
+	return nil
+}
+
+//ObjectiveValue returns the objective value of the optimized model
+func (m *Model) ObjectiveValue() (float64, error) {
+	if m.lp == nil {
+		return 0, SolutionUnavailableError{"model is unsolved; try optimizing model first!"}
+	}
+	if _, err := m.lp.Solution(); err != nil {
+		return 0, err
+	}
+	return m.lp.ObjectiveValue(), nil
+}
+
+//Solution returns the value of each variable in the optimized model, keyed by name
+func (m *Model) Solution() (map[string]float64, error) {
+	if m.lp == nil {
+		return nil, SolutionUnavailableError{"model is unsolved; try optimizing model first!"}
+	}
+	sol, err := m.lp.Solution()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(m.vars))
+	for _, v := range m.vars {
+		values[v.name] = 0
+		if v.index < len(sol) {
+			values[v.name] = sol[v.index]
+		}
+	}
+	return values, nil
+}
+
+//Value returns the value of the named variable in the optimized model
+func (m *Model) Value(name string) (float64, error) {
+	sol, err := m.Solution()
+	if err != nil {
+		return 0, err
+	}
+	value, ok := sol[name]
+	if !ok {
+		return 0, InvalidInputError{s: fmt.Sprintf("no variable named %q", name)}
+	}
+	return value, nil
+}
+
+func (m *Model) validate() error {
+	names := make(map[string]bool, len(m.vars))
+	for _, v := range m.vars {
+		if names[v.name] {
+			return InvalidInputError{s: fmt.Sprintf("duplicate variable name %q", v.name)}
+		}
+		names[v.name] = true
+		if v.lower > v.upper {
+			return InvalidInputError{s: fmt.Sprintf("lower bound of %q exceeds its upper bound", v.name)}
+		}
+	}
+	exprs := []Expr{m.objective}
+	for _, c := range m.constraints {
+		exprs = append(exprs, c.expr)
+	}
+	for _, e := range exprs {
+		for v := range e.terms {
+			if v.model != m {
+				return InvalidInputError{s: fmt.Sprintf("variable %q belongs to another model", v.name)}
+			}
+		}
+	}
+	return nil
+}
+
+//Name returns the name of the variable
+func (v *Var) Name() string {
+	return v.name
+}
+
+//Index returns the column of the variable in the compiled LP
+func (v *Var) Index() int {
+	return v.index
+}
+
+//SetBounds sets the bounds lower <= v <= upper; use math.Inf(1) for no upper bound
+func (v *Var) SetBounds(lower, upper float64) {
+	v.lower = lower
+	v.upper = upper
+}
+
+//Bounds returns the lower and upper bound of the variable
+func (v *Var) Bounds() (float64, float64) {
+	if v.binary {
+		return 0, 1
+	}
+	return v.lower, v.upper
+}
+
+//SetInteger marks the variable as integer
+func (v *Var) SetInteger() {
+	v.integer = true
+}
+
+//SetBinary marks the variable as integer with 0 <= v <= 1
+func (v *Var) SetBinary() {
+	v.integer = true
+	v.binary = true
+}
+
+//IsInteger returns true if the variable is marked as integer or binary
+func (v *Var) IsInteger() bool {
+	return v.integer
+}
+
+//IsBinary returns true if the variable is marked as binary
+func (v *Var) IsBinary() bool {
+	return v.binary
+}
+
+//Mul returns the expression factor * v
+func (v *Var) Mul(factor float64) Expr {
+	return v.toExpr().Mul(factor)
+}
+
+//Plus returns the expression v + other
+func (v *Var) Plus(other Linear) Expr {
+	return v.toExpr().Plus(other)
+}
+
+//Minus returns the expression v - other
+func (v *Var) Minus(other Linear) Expr {
+	return v.toExpr().Minus(other)
+}
+
+//Eq returns the constraint v = rhs
+func (v *Var) Eq(rhs float64) Constraint {
+	return v.toExpr().Eq(rhs)
+}
+
+//Leq returns the constraint v <= rhs
+func (v *Var) Leq(rhs float64) Constraint {
+	return v.toExpr().Leq(rhs)
+}
+
+//Geq returns the constraint v >= rhs
+func (v *Var) Geq(rhs float64) Constraint {
+	return v.toExpr().Geq(rhs)
+}
+
+func (v *Var) toExpr() Expr {
+	return Expr{terms: map[*Var]float64{v: 1}}
+}
+
+//Constant returns the expression consisting of only the constant c
+func Constant(c float64) Expr {
+	return Expr{constant: c, terms: map[*Var]float64{}}
+}
+
+//Sum returns the sum of the given linear expressions
+func Sum(terms ...Linear) Expr {
+	sum := Constant(0)
+	for _, t := range terms {
+		sum = sum.Plus(t)
+	}
+	return sum
+}
+
+//Constant returns the constant term of the expression
+func (e Expr) Constant() float64 {
+	return e.constant
+}
+
+//Coefficient returns the coefficient of v in the expression
+func (e Expr) Coefficient(v *Var) float64 {
+	return e.terms[v]
+}
+
+//Plus returns the expression e + other
+func (e Expr) Plus(other Linear) Expr {
+	return e.combine(1, other.toExpr())
+}
+
+//Minus returns the expression e - other
+func (e Expr) Minus(other Linear) Expr {
+	return e.combine(-1, other.toExpr())
+}
+
+//PlusConstant returns the expression e + c
+func (e Expr) PlusConstant(c float64) Expr {
+	return e.combine(1, Constant(c))
+}
+
+//Mul returns the expression factor * e
+func (e Expr) Mul(factor float64) Expr {
+	return Constant(0).combine(factor, e)
+}
+
+//Eq returns the constraint e = rhs
+func (e Expr) Eq(rhs float64) Constraint {
+	return Constraint{expr: e, relation: relationEq, rhs: rhs}
+}
+
+//Leq returns the constraint e <= rhs
+func (e Expr) Leq(rhs float64) Constraint {
+	return Constraint{expr: e, relation: relationLeq, rhs: rhs}
+}
+
+//Geq returns the constraint e >= rhs
+func (e Expr) Geq(rhs float64) Constraint {
+	return Constraint{expr: e, relation: relationGeq, rhs: rhs}
+}
+
+func (e Expr) toExpr() Expr {
+	return e
+}
+
+// returns e + factor * other without modifying either expression
+func (e Expr) combine(factor float64, other Expr) Expr {
+	result := Expr{constant: e.constant + factor*other.constant, terms: map[*Var]float64{}}
+	for v, c := range e.terms {
+		result.terms[v] = c
+	}
+	for v, c := range other.terms {
+		result.terms[v] += factor * c
+	}
+	return result
+}
+
+// dense coefficient vector of length n
+func (e Expr) coefficients(n int) []float64 {
+	coefs := make([]float64, n)
+	for v, c := range e.terms {
+		coefs[v.index] = c
+	}
+	return coefs
+}
+
+//Named returns a copy of the constraint with the given name
+func (c Constraint) Named(name string) Constraint {
+	c.name = name
+	return c
+}
+
+//Name returns the name of the constraint
+func (c Constraint) Name() string {
+	return c.name
+}
@@ -0,0 +1,155 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpr_Arithmetic(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	e := x.Mul(3).Plus(y).Minus(x).PlusConstant(4).Mul(2)
+	assert.Equal(t, float64(4), e.Coefficient(x))
+	assert.Equal(t, float64(2), e.Coefficient(y))
+	assert.Equal(t, float64(8), e.Constant())
+	assert.Equal(t, []float64{4, 2}, e.coefficients(2))
+
+	s := Sum(x, y, x.Mul(2), Constant(1))
+	assert.Equal(t, float64(3), s.Coefficient(x))
+	assert.Equal(t, float64(1), s.Coefficient(y))
+	assert.Equal(t, float64(1), s.Constant())
+}
+
+func TestModel_LP(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	m.Maximize(x.Mul(3).Plus(y.Mul(2)))
+	m.Add(x.Plus(y).Eq(4))
+	m.Add(x.Mul(2).Plus(y).Leq(6))
+	m.Add(y.Geq(1))
+	lp, err := m.LP()
+	assert.NoError(t, err)
+	expected := tableau{
+		{0, 3, 2, 0, 0},
+		{4, 1, 1, 0, 0},
+		{6, 2, 1, 1, 0},
+		{1, 0, 1, 0, -1},
+	}
+	assert.Equal(t, expected, lp.tableau)
+}
+
+func TestModel_Optimize(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	m.Maximize(x.Mul(3).Plus(y.Mul(5)))
+	m.Add(x.Leq(4))
+	m.Add(y.Mul(2).Leq(12))
+	m.Add(x.Mul(3).Plus(y.Mul(2)).Leq(18))
+
+	_, err := m.Solution()
+	assert.Error(t, err)
+
+	assert.NoError(t, m.Optimize())
+	sol, err := m.Solution()
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, sol["x"], EPSILON)
+	assert.InDelta(t, 6, sol["y"], EPSILON)
+	obj, err := m.ObjectiveValue()
+	assert.NoError(t, err)
+	assert.InDelta(t, 36, obj, EPSILON)
+	v, err := m.Value("y")
+	assert.NoError(t, err)
+	assert.InDelta(t, 6, v, EPSILON)
+	_, err = m.Value("z")
+	assert.Error(t, err)
+}
+
+func TestModel_Minimize(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	m.Minimize(x.Plus(y))
+	m.Add(x.Plus(y).Leq(4))
+	assert.NoError(t, m.Optimize())
+	sol, _ := m.Solution()
+	assert.InDelta(t, 0, sol["x"], EPSILON)
+	assert.InDelta(t, 0, sol["y"], EPSILON)
+	obj, _ := m.ObjectiveValue()
+	assert.InDelta(t, 0, obj, EPSILON)
+
+	m = NewModel()
+	x = m.NewVar("x")
+	y = m.NewVar("y")
+	m.Minimize(x.Mul(2).Plus(y.Mul(3)).PlusConstant(1))
+	m.Add(x.Plus(y).Geq(4))
+	m.Add(x.Geq(1))
+	m.Add(y.Leq(10))
+	for _, engine := range []int{TABLEAU, REVISED, EXACT, SPARSE} {
+		lp, err := m.LP()
+		assert.NoError(t, err)
+		lp.SetEngine(engine)
+		result := lp.Optimize()
+		assert.Equal(t, OPTIMAL, result.Status, engine)
+		assert.InDelta(t, 9, result.ObjectiveValue, EPSILON, engine)
+		assert.InDelta(t, 9, lp.ObjectiveValue(), EPSILON, engine)
+		assert.InDeltaSlice(t, []float64{4, 0}, result.Solution[:2], EPSILON, engine)
+		// raising the lower limit of x + y by 1 costs 2
+		assert.InDeltaSlice(t, []float64{2, 0, 0}, result.Duals, EPSILON, engine)
+		if engine == EXACT {
+			exact, _ := lp.ExactObjectiveValue()
+			assert.Equal(t, "9", exact.RatString())
+		}
+	}
+	assert.NoError(t, m.Optimize())
+	obj, _ = m.ObjectiveValue()
+	assert.InDelta(t, 9, obj, EPSILON)
+
+	// unbounded below
+	m = NewModel()
+	x = m.NewVar("x")
+	m.Minimize(x.Mul(-1))
+	assert.NoError(t, m.Optimize())
+	_, err := m.Solution()
+	assert.IsType(t, UnboundedError{}, err)
+}
+
+func TestModel_Infeasible(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("")
+	assert.Equal(t, "x1", x.Name())
+	m.Maximize(x)
+	m.Add(x.Leq(1))
+	m.Add(x.Geq(2))
+	assert.NoError(t, m.Optimize())
+	_, err := m.Solution()
+	assert.IsType(t, InfeasibleError{}, err)
+}
+
+func TestModel_Validate(t *testing.T) {
+	m := NewModel()
+	m.NewVar("x")
+	m.NewVar("x")
+	assert.Error(t, m.Optimize())
+
+	m = NewModel()
+	other := NewModel().NewVar("y")
+	m.Maximize(m.NewVar("x").Plus(other))
+	assert.Error(t, m.Optimize())
+}
+
+func TestModel_ComputeIIS(t *testing.T) {
+	m := NewModel()
+	x := m.NewVar("x")
+	y := m.NewVar("y")
+	m.Maximize(x.Plus(y))
+	m.Add(x.Plus(y).Leq(10).Named("capacity"))
+	m.Add(x.Geq(4))
+	m.Add(y.Leq(3).Named("limit"))
+	m.Add(y.Geq(7))
+	names, err := m.ComputeIIS()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"limit", "c4"}, names)
+}
@@ -0,0 +1,277 @@
+package sago
+
+import "math"
+
+//PivotRule chooses the entering and leaving variables of an iteration of the
+//tableau engine. Row 0 of the tableau t is the objective function, in which a
+//negative entry means the column improves the objective; rows 1, 2, ... are
+//the constraints (b, a1, a2, ...). basis holds the index of the basic variable
+//of each constraint (column basis[i]+1 of row i+1), or -1. tol holds the
+//tolerances of the LP, with the defaults filled in. For LPs with bounds, the
+//ratio test of the bounded-variable simplex chooses the leaving variable
+//without calling Leaving, so no rule prevents cycling on them.
+type PivotRule interface {
+	//Entering returns the column of the entering variable, or 0 if no column
+	//improves the objective
+	Entering(t [][]float64, basis []int, tol Tolerances) int
+	//Leaving returns the row of the leaving variable when column enters the
+	//basis, or 0 if column is unbounded
+	Leaving(t [][]float64, basis []int, column int, tol Tolerances) int
+}
+
+//Bland enters the improving variable with the lowest index and breaks ties in
+//the ratio test by the lowest index of the basic variable. It never cycles.
+type Bland struct{}
+
+//Entering returns the first column with a negative objective function coefficient
+func (Bland) Entering(t [][]float64, basis []int, tol Tolerances) int {
+	for j, c := range t[0][1:] {
+		if Flt(c, 0, tol.DualFeasibility) {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+//Leaving returns the row with the lowest ratio whose basic variable has the lowest index
+func (Bland) Leaving(t [][]float64, basis []int, column int, tol Tolerances) int {
+	rows := ratioTies(t, column, tol)
+	if len(rows) == 0 {
+		return 0
+	}
+	row := rows[0]
+	for _, r := range rows[1:] {
+		if basicVariable(basis, r) < basicVariable(basis, row) {
+			row = r
+		}
+	}
+	return row
+}
+
+//Lexicographic enters the first improving variable and breaks ties in the
+//ratio test by comparing the candidate rows over b and the columns of the
+//basis the simplex algorithm started from, divided by their entry in the
+//entering column, lexicographically. Those columns start as the identity and
+//hold B^-1 times the starting basis, so the rows stay lexicographically
+//positive, no two rows tie and the simplex algorithm cannot cycle. A
+//Lexicographic rule keeps the starting basis; use one per LP.
+type Lexicographic struct {
+	start []int
+}
+
+//NewLexicographic creates a Lexicographic pivot rule
+func NewLexicographic() *Lexicographic {
+	return &Lexicographic{}
+}
+
+//Entering returns the first column with a negative objective function coefficient
+func (l *Lexicographic) Entering(t [][]float64, basis []int, tol Tolerances) int {
+	return Bland{}.Entering(t, basis, tol)
+}
+
+//Leaving returns the lexicographically smallest row among those with the
+//lowest ratio. Before the tableau engine starts, the current basis is taken
+//as the starting basis.
+func (l *Lexicographic) Leaving(t [][]float64, basis []int, column int, tol Tolerances) int {
+	rows := ratioTies(t, column, tol)
+	if len(rows) == 0 {
+		return 0
+	}
+	start := l.start
+	if start == nil {
+		start = basis
+	}
+	row := rows[0]
+	for _, r := range rows[1:] {
+		if lexicographicLess(t, start, r, row, column, tol) {
+			row = r
+		}
+	}
+	return row
+}
+
+func (l *Lexicographic) restart(basis []int) {
+	l.start = append([]int{}, basis...)
+}
+
+// true if row r divided by its entry in column is lexicographically smaller
+// than row s over b and the columns of the variables in start
+func lexicographicLess(t [][]float64, start []int, r, s, column int, tol Tolerances) bool {
+	compare := func(j int) (bool, bool) {
+		a := t[r][j] / t[r][column]
+		b := t[s][j] / t[s][column]
+		if Feq(a, b, tol.Zero) {
+			return false, false
+		}
+		return a < b, true
+	}
+	if less, differ := compare(0); differ {
+		return less
+	}
+	for _, v := range start {
+		if v < 0 || v+1 >= len(t[r]) {
+			continue
+		}
+		if less, differ := compare(v + 1); differ {
+			return less
+		}
+	}
+	return false
+}
+
+//Dantzig enters the variable with the most negative objective function
+//coefficient (the largest coefficient rule)
+type Dantzig struct{}
+
+//Entering returns the column with the most negative objective function coefficient
+func (Dantzig) Entering(t [][]float64, basis []int, tol Tolerances) int {
+	col := 0
+	for j, c := range t[0][1:] {
+		if Flt(c, 0, tol.DualFeasibility) && (col == 0 || c < t[0][col]) {
+			col = j + 1
+		}
+	}
+	return col
+}
+
+//Leaving returns the first row with the lowest ratio
+func (Dantzig) Leaving(t [][]float64, basis []int, column int, tol Tolerances) int {
+	return firstRatio(t, column, tol)
+}
+
+//SteepestEdge enters the variable whose edge improves the objective the most
+//per unit of distance, i.e. the most negative c_j / ||(1, a_j)||. The tableau
+//holds every column, so the exact edge lengths are used.
+type SteepestEdge struct{}
+
+//Entering returns the column with the steepest improving edge
+func (SteepestEdge) Entering(t [][]float64, basis []int, tol Tolerances) int {
+	col := 0
+	best := 0.0
+	for j, c := range t[0][1:] {
+		if !Flt(c, 0, tol.DualFeasibility) {
+			continue
+		}
+		norm := 1.0
+		for _, row := range t[1:] {
+			norm += row[j+1] * row[j+1]
+		}
+		if score := c * c / norm; score > best {
+			col, best = j+1, score
+		}
+	}
+	return col
+}
+
+//Leaving returns the first row with the lowest ratio
+func (SteepestEdge) Leaving(t [][]float64, basis []int, column int, tol Tolerances) int {
+	return firstRatio(t, column, tol)
+}
+
+//Devex approximates steepest edge pricing with reference weights that are
+//updated after every pivot. A Devex rule keeps state; use one per LP.
+type Devex struct {
+	weights []float64
+}
+
+//NewDevex creates a Devex pivot rule
+func NewDevex() *Devex {
+	return &Devex{}
+}
+
+//Entering returns the column with the largest c_j^2 / w_j among improving columns
+func (d *Devex) Entering(t [][]float64, basis []int, tol Tolerances) int {
+	if len(d.weights) != len(t[0]) {
+		// new tableau; start a new reference framework
+		d.weights = make([]float64, len(t[0]))
+		for j := range d.weights {
+			d.weights[j] = 1
+		}
+	}
+	col := 0
+	best := 0.0
+	for j, c := range t[0][1:] {
+		if !Flt(c, 0, tol.DualFeasibility) {
+			continue
+		}
+		if score := c * c / d.weights[j+1]; score > best {
+			col, best = j+1, score
+		}
+	}
+	return col
+}
+
+//Leaving returns the first row with the lowest ratio and updates the reference
+//weights for the pivot on that row
+func (d *Devex) Leaving(t [][]float64, basis []int, column int, tol Tolerances) int {
+	row := firstRatio(t, column, tol)
+	if row == 0 || len(d.weights) != len(t[0]) {
+		return row
+	}
+	alpha := t[row][column]
+	wq := d.weights[column]
+	for j := 1; j < len(t[row]); j++ {
+		if j != column && t[row][j] != 0 {
+			d.weights[j] = math.Max(d.weights[j], wq*(t[row][j]/alpha)*(t[row][j]/alpha))
+		}
+	}
+	if row-1 < len(basis) && basis[row-1] >= 0 {
+		d.weights[basis[row-1]+1] = math.Max(wq/(alpha*alpha), 1)
+	}
+	return row
+}
+
+//GetPivotRule returns the pivot rule of the tableau engine, or nil for the default rule
+func (lp *LP) GetPivotRule() PivotRule {
+	return lp.pivotRule
+}
+
+//SetPivotRule sets the pivot rule of the tableau engine. The default rule (nil)
+//enters the first improving variable and leaves the first row with the lowest ratio.
+func (lp *LP) SetPivotRule(rule PivotRule) {
+	lp.pivotRule = rule
+}
+
+// pivot rules that depend on the basis the simplex algorithm starts from; the
+// tableau engine passes it at the start of each phase
+type restartingRule interface {
+	restart(basis []int)
+}
+
+// returns the rows (index 1 is the first constraint) with the lowest ratio b_i / a_i
+func ratioTies(t [][]float64, column int, tol Tolerances) []int {
+	var rows []int
+	low := math.MaxFloat64
+	for i, c := range t[1:] {
+		if Fle(c[column], 0, tol.Pivot) { // divide by 0
+			continue
+		}
+		ratio := c[0] / c[column]
+		if !Fle(0, ratio, tol.Zero) {
+			continue
+		}
+		if Flt(ratio, low, tol.Zero) {
+			rows, low = []int{i + 1}, ratio
+		} else if Feq(ratio, low, tol.Zero) {
+			rows = append(rows, i+1)
+		}
+	}
+	return rows
+}
+
+func firstRatio(t [][]float64, column int, tol Tolerances) int {
+	rows := ratioTies(t, column, tol)
+	if len(rows) == 0 {
+		return 0
+	}
+	return rows[0]
+}
+
+// index of the basic variable of the row (index 1 is the first constraint);
+// rows without a basic variable come last
+func basicVariable(basis []int, row int) int {
+	if row-1 < len(basis) && basis[row-1] >= 0 {
+		return basis[row-1]
+	}
+	return math.MaxInt32
+}
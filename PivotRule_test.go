@@ -0,0 +1,145 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func pivotRules() map[string]func() PivotRule {
+	return map[string]func() PivotRule{
+		"Bland":         func() PivotRule { return Bland{} },
+		"Lexicographic": func() PivotRule { return NewLexicographic() },
+		"Dantzig":       func() PivotRule { return Dantzig{} },
+		"SteepestEdge":  func() PivotRule { return SteepestEdge{} },
+		"Devex":         func() PivotRule { return NewDevex() },
+	}
+}
+
+func TestPivotRule_Entering(t *testing.T) {
+	tab := [][]float64{
+		{0, -1, -3, 0, -2},
+		{4, 1, 3, 1, 0},
+		{6, 1, 1, 0, 1},
+	}
+	basis := []int{2, 3}
+	tol := DefaultTolerances()
+	assert.Equal(t, 1, Bland{}.Entering(tab, basis, tol))
+	assert.Equal(t, 1, NewLexicographic().Entering(tab, basis, tol))
+	assert.Equal(t, 2, Dantzig{}.Entering(tab, basis, tol))
+	// 9 / 11 for column 2 and 4 / 2 for column 4
+	assert.Equal(t, 4, SteepestEdge{}.Entering(tab, basis, tol))
+	assert.Equal(t, 2, NewDevex().Entering(tab, basis, tol))
+
+	tab[0] = []float64{0, 1, 0, 0, 2}
+	for name, rule := range pivotRules() {
+		assert.Equal(t, 0, rule().Entering(tab, basis, tol), name)
+	}
+
+	// reduced costs within the dual feasibility tolerance do not improve
+	tab[0] = []float64{0, -.01, 0, 0, 2}
+	tol.DualFeasibility = .1
+	for name, rule := range pivotRules() {
+		assert.Equal(t, 0, rule().Entering(tab, basis, tol), name)
+	}
+}
+
+func TestPivotRule_Leaving(t *testing.T) {
+	tab := [][]float64{
+		{0, -1, -1, 0, 0, 0},
+		{2, 2, 1, 0, 0, 1},
+		{1, 1, 3, 1, 0, 0},
+		{5, 0, 1, 0, 1, 0},
+	}
+	basis := []int{4, 2, 3}
+	tol := DefaultTolerances()
+	// rows 1 and 2 tie with ratio 1 for column 1
+	assert.Equal(t, 1, Dantzig{}.Leaving(tab, basis, 1, tol))
+	assert.Equal(t, 2, Bland{}.Leaving(tab, basis, 1, tol))
+	// over b and the columns of the basis, (1, .5, 0, 0) > (1, 0, 1, 0)
+	lex := NewLexicographic()
+	assert.Equal(t, 2, lex.Leaving(tab, basis, 1, tol))
+	// over b and the columns of the starting basis, (1, 0, 0, .5) < (1, 1, 0, 0)
+	lex.restart([]int{2, 3, 4})
+	assert.Equal(t, 1, lex.Leaving(tab, basis, 1, tol))
+	tab[1][0] = 3
+	assert.Equal(t, 2, lex.Leaving(tab, basis, 1, tol))
+
+	tab[1][1], tab[2][1] = -1, 0
+	for name, rule := range pivotRules() {
+		assert.Equal(t, 0, rule().Leaving(tab, basis, 1, tol), name)
+	}
+}
+
+func TestDevex_Leaving(t *testing.T) {
+	tab := [][]float64{
+		{0, -1, -3, 0, 0},
+		{4, 1, 2, 1, 0},
+		{6, 1, 1, 0, 1},
+	}
+	d := NewDevex()
+	col := d.Entering(tab, []int{2, 3}, DefaultTolerances())
+	assert.Equal(t, 2, col)
+	assert.Equal(t, 1, d.Leaving(tab, []int{2, 3}, col, DefaultTolerances()))
+	assert.Equal(t, []float64{1, 1, 1, 1, 1}, d.weights)
+
+	d.weights = []float64{1, 1, 4, 1, 1}
+	d.Leaving(tab, []int{2, 3}, col, DefaultTolerances())
+	assert.Equal(t, []float64{1, 1, 4, 1, 1}, d.weights)
+	tab[1][1] = 4
+	d.Leaving(tab, []int{2, 3}, col, DefaultTolerances())
+	assert.Equal(t, []float64{1, 16, 4, 1, 1}, d.weights)
+}
+
+func TestLP_SetPivotRule(t *testing.T) {
+	lp := NewLP()
+	assert.Nil(t, lp.GetPivotRule())
+	lp.SetPivotRule(Bland{})
+	assert.Equal(t, Bland{}, lp.GetPivotRule())
+}
+
+// Beale's example cycles with the largest coefficient rule when ties are
+// broken by the lowest row
+func TestLP_OptimizeBeale(t *testing.T) {
+	for name, rule := range pivotRules() {
+		if name == "Dantzig" {
+			continue
+		}
+		lp := NewLP()
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 0, 0, 0, .75, -20, .5, -6)
+		lp.AddConstraintEq(0, 1, 0, 0, .25, -8, -1, 9)
+		lp.AddConstraintEq(0, 0, 1, 0, .5, -12, -.5, 3)
+		lp.AddConstraintEq(1, 0, 0, 1, 0, 0, 1, 0)
+		lp.SetPivotRule(rule())
+		lp.Optimize()
+		assert.True(t, lp.Optimal(), name)
+		assert.InDelta(t, 1.25, lp.ObjectiveValue(), EPSILON, name)
+	}
+}
+
+func TestLP_OptimizePivotRules(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for k := 0; k < 5; k++ {
+		expected := randomLP(r, 10, 15)
+		expected.Optimize()
+		for name, rule := range pivotRules() {
+			lp := NewLP()
+			lp.tableau = expected.original.copy()
+			lp.width = expected.width
+			lp.SetPivotRule(rule())
+			lp.Optimize()
+			assert.True(t, lp.Optimal(), name)
+			assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6, name)
+		}
+	}
+	for _, name := range []string{"LP_feas_sef", "LP_degenerate_iteration", "LP_sol_0"} {
+		expected := readLP(name)
+		expected.Optimize()
+		for ruleName, rule := range pivotRules() {
+			lp := readLP(name)
+			lp.SetPivotRule(rule())
+			lp.Optimize()
+			assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6, name+" "+ruleName)
+		}
+	}
+}
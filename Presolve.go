@@ -0,0 +1,502 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+)
+
+//Presolved is an LP reduced by Presolve, with what is needed to map the
+//solutions of the reduced LP back to the LP as built
+type Presolved struct {
+	//LP is the reduced LP, or nil if presolve fixed every variable
+	LP *LP
+	//Columns holds the index in the LP as built of each variable of LP
+	Columns []int
+	//Rows holds the index in the LP as built of each constraint of LP
+	Rows []int
+
+	fixed   []float64 // value of each variable removed by presolve, NaN for the others
+	slackOf []int     // constraint whose slack variable each variable is, or -1
+	built   tableau   // tableau of the LP as built
+}
+
+// a constraint lo <= a1x1 + a2x2 + ... <= hi over the variables other than the
+// slack variables
+type presolveRow struct {
+	coefs   map[int]float64
+	lo, hi  float64
+	removed bool
+}
+
+type presolver struct {
+	rows         []presolveRow
+	lower, upper []float64
+	c            []float64 // objective function coefficients
+	z            float64   // objective function constant
+	sense        float64   // 1 when maximizing, -1 when minimizing
+	integer      []bool
+	slackOf      []int
+	fixed        []float64
+	tolerance    float64
+}
+
+//SetPresolve makes Optimize presolve the LP, optimize the reduced LP and map its
+//solution back to the LP as built, from where the simplex algorithm checks its
+//optimality. Iterations on the reduced LP are not traced. The EXACT engine does
+//not presolve.
+func (lp *LP) SetPresolve(presolve bool) {
+	lp.reset()
+	lp.presolve = presolve
+}
+
+//Presolve reduces the LP by removing empty constraints, turning constraints
+//over a single variable into bounds, substituting fixed variables, removing
+//variables without constraints or dominated by one of their bounds and merging
+//parallel constraints. The LP itself is not modified. Returns an InfeasibleError
+//or an UnboundedError, without certificates, if presolve finds that the LP has
+//no optimal solution.
+func (lp *LP) Presolve() (*Presolved, error) {
+	lp.densify()
+	built := lp.tableau
+	if lp.dirty {
+		built = lp.original
+	}
+	built = built.copy()
+	p := newPresolver(lp, built)
+	if err := p.reduce(); err != nil {
+		return nil, err
+	}
+
+	result := &Presolved{fixed: p.fixed, slackOf: p.slackOf, built: built}
+	var c []float64
+	for j, v := range p.fixed {
+		if math.IsNaN(v) && p.slackOf[j] < 0 {
+			result.Columns = append(result.Columns, j)
+			c = append(c, p.c[j])
+		}
+	}
+	if len(result.Columns) == 0 {
+		return result, nil
+	}
+	reduced := NewLP()
+	reduced.SetObjectiveFunction(lp.objective, p.z, c...)
+	for k, j := range result.Columns {
+		if p.lower[j] != 0 || !math.IsInf(p.upper[j], 1) {
+			reduced.SetBounds(k, p.lower[j], p.upper[j])
+		}
+		if lp.binaries[j] {
+			reduced.SetBinary(k)
+		} else if lp.integers[j] {
+			reduced.SetInteger(k)
+		}
+	}
+	for i, row := range p.rows {
+		if row.removed {
+			continue
+		}
+		result.Rows = append(result.Rows, i)
+		coefs := make([]float64, len(result.Columns))
+		for k, j := range result.Columns {
+			coefs[k] = row.coefs[j]
+		}
+		switch {
+		case Feq(row.lo, row.hi, p.tolerance):
+			reduced.AddConstraintEq(row.hi, coefs...)
+		case math.IsInf(row.lo, -1):
+			reduced.AddConstraintGeq(row.hi, coefs...)
+		case math.IsInf(row.hi, 1):
+			reduced.AddConstraintLeq(row.lo, coefs...)
+		default:
+			reduced.AddConstraintRange(row.lo, row.hi, coefs...)
+		}
+	}
+	reduced.engine = lp.engine
+	reduced.pivotRule = lp.pivotRule
+	reduced.tolerances = lp.tolerances
+	reduced.scaling = lp.scaling
+	reduced.mipOptions = lp.mipOptions
+	result.LP = reduced
+	return result, nil
+}
+
+//Postsolve maps a solution of the variables of the reduced LP to a solution of
+//every variable of the LP as built, including its slack variables
+func (p *Presolved) Postsolve(solution []float64) []float64 {
+	x := make([]float64, len(p.fixed))
+	for j, v := range p.fixed {
+		if !math.IsNaN(v) {
+			x[j] = v
+		}
+	}
+	for k, j := range p.Columns {
+		x[j] = solution[k]
+	}
+	// b = a1x1 + a2x2 + ... + a_s s
+	for s, i := range p.slackOf {
+		if i < 0 {
+			continue
+		}
+		row := p.built[i+1]
+		v := row[0]
+		for j, a := range row[1:] {
+			if j != s {
+				v -= a * x[j]
+			}
+		}
+		x[s] = v / row[s+1]
+	}
+	return x
+}
+
+func newPresolver(lp *LP, built tableau) *presolver {
+	n := len(built[0]) - 1
+	p := &presolver{
+		rows:      make([]presolveRow, len(built)-1),
+		lower:     make([]float64, n),
+		upper:     make([]float64, n),
+		c:         make([]float64, n),
+		z:         built[0][0],
+		sense:     lp.sense(),
+		integer:   make([]bool, n),
+		slackOf:   make([]int, n),
+		fixed:     make([]float64, n),
+		tolerance: lp.GetTolerances().PrimalFeasibility,
+	}
+	for j := range p.c {
+		p.lower[j], p.upper[j] = lp.Bounds(j)
+		p.c[j] = built[0][j+1]
+		p.integer[j] = lp.integers[j]
+		p.slackOf[j] = -1
+		p.fixed[j] = math.NaN()
+	}
+	// a slack variable appears in its own constraint only
+	for i := range p.rows {
+		s := lp.rowInfo(i).slack
+		if s < 0 || s >= n || p.c[s] != 0 || p.integer[s] || built[i+1][s+1] == 0 {
+			continue
+		}
+		alone := true
+		for r, row := range built[1:] {
+			alone = alone && (r == i || row[s+1] == 0)
+		}
+		if alone {
+			p.slackOf[s] = i
+		}
+	}
+	for i, row := range built[1:] {
+		r := presolveRow{coefs: map[int]float64{}, lo: row[0], hi: row[0]}
+		for j, a := range row[1:] {
+			if a == 0 {
+				continue
+			}
+			if p.slackOf[j] != i {
+				r.coefs[j] = a
+				continue
+			}
+			// a1x1 + a2x2 + ... = b - a_s s
+			lo, hi := row[0]-a*p.upper[j], row[0]-a*p.lower[j]
+			if a < 0 {
+				lo, hi = hi, lo
+			}
+			r.lo, r.hi = lo, hi
+		}
+		p.rows[i] = r
+	}
+	return p
+}
+
+// applies the reductions until none applies
+func (p *presolver) reduce() error {
+	for changed := true; changed; {
+		changed = false
+		for i := range p.rows {
+			row := &p.rows[i]
+			if row.removed {
+				continue
+			}
+			reduced, err := p.reduceRow(i, row)
+			if err != nil {
+				return err
+			}
+			changed = changed || reduced
+		}
+		for j, v := range p.fixed {
+			if !math.IsNaN(v) || p.slackOf[j] >= 0 {
+				continue
+			}
+			reduced, err := p.reduceColumn(j)
+			if err != nil {
+				return err
+			}
+			changed = changed || reduced
+		}
+		reduced, err := p.mergeParallelRows()
+		if err != nil {
+			return err
+		}
+		changed = changed || reduced
+	}
+	return nil
+}
+
+// removes the constraint if it is empty, free or over a single variable, whose
+// bounds it then tightens
+func (p *presolver) reduceRow(i int, row *presolveRow) (bool, error) {
+	switch {
+	case math.IsInf(row.lo, -1) && math.IsInf(row.hi, 1):
+	case len(row.coefs) == 0:
+		if Fgt(row.lo, 0, p.tolerance) || Flt(row.hi, 0, p.tolerance) {
+			return false, InfeasibleError{s: fmt.Sprintf("constraint %d has no variables but excludes 0", i)}
+		}
+	case len(row.coefs) == 1:
+		for j, a := range row.coefs {
+			lo, hi := row.lo/a, row.hi/a
+			if a < 0 {
+				lo, hi = hi, lo
+			}
+			if err := p.tighten(j, lo, hi); err != nil {
+				return false, err
+			}
+		}
+	default:
+		return false, nil
+	}
+	row.removed = true
+	return true, nil
+}
+
+// tightens the bounds of variable j to lo <= x_j <= hi
+func (p *presolver) tighten(j int, lo, hi float64) error {
+	if p.integer[j] {
+		lo, hi = math.Ceil(lo-integralityTolerance), math.Floor(hi+integralityTolerance)
+	}
+	p.lower[j] = math.Max(p.lower[j], lo)
+	p.upper[j] = math.Min(p.upper[j], hi)
+	switch {
+	case Fgt(p.lower[j], p.upper[j], p.tolerance):
+		return InfeasibleError{s: fmt.Sprintf("the bounds of variable %d exclude each other", j)}
+	case p.lower[j] > p.upper[j]:
+		p.upper[j] = p.lower[j]
+	}
+	return nil
+}
+
+// fixes the variable if its bounds are equal, if it has no constraints or if it
+// is dominated by one of its bounds: moving towards the bound does not worsen
+// the objective function or violate a constraint
+func (p *presolver) reduceColumn(j int) (bool, error) {
+	if Feq(p.lower[j], p.upper[j], p.tolerance) {
+		p.fix(j, p.lower[j])
+		return true, nil
+	}
+	// the objective function improves as x_j increases for gain > 0
+	gain := p.sense * p.c[j]
+	toLower, toUpper := gain <= 0, gain >= 0
+	for _, row := range p.rows {
+		a := row.coefs[j]
+		if row.removed || a == 0 {
+			continue
+		}
+		// decreasing x_j decreases the row for a > 0, increasing x_j increases it
+		toLower = toLower && (a > 0 && math.IsInf(row.lo, -1) || a < 0 && math.IsInf(row.hi, 1))
+		toUpper = toUpper && (a > 0 && math.IsInf(row.hi, 1) || a < 0 && math.IsInf(row.lo, -1))
+	}
+	var v float64
+	switch {
+	case toUpper && gain > 0:
+		if math.IsInf(p.upper[j], 1) {
+			return false, UnboundedError{s: fmt.Sprintf("LP is unbounded or infeasible: variable %d improves the objective function without limit", j), Entering: j}
+		}
+		v = p.upper[j]
+	case toLower && !math.IsInf(p.lower[j], -1):
+		v = p.lower[j]
+	case toUpper && !math.IsInf(p.upper[j], 1):
+		v = p.upper[j]
+	default:
+		return false, nil
+	}
+	if p.integer[j] && v != math.Round(v) {
+		return false, nil
+	}
+	p.fix(j, v)
+	return true, nil
+}
+
+// substitutes the value v for variable j
+func (p *presolver) fix(j int, v float64) {
+	p.fixed[j] = v
+	p.z += p.c[j] * v
+	for i := range p.rows {
+		row := &p.rows[i]
+		if a, ok := row.coefs[j]; ok {
+			row.lo -= a * v
+			row.hi -= a * v
+			delete(row.coefs, j)
+		}
+	}
+}
+
+// merges each constraint into an earlier one whose coefficients are a multiple
+// of its own, intersecting their limits
+func (p *presolver) mergeParallelRows() (bool, error) {
+	merged := false
+	for k := range p.rows {
+		row := &p.rows[k]
+		if row.removed {
+			continue
+		}
+		for i := 0; i < k; i++ {
+			if p.rows[i].removed {
+				continue
+			}
+			ratio, ok := p.parallel(p.rows[i].coefs, row.coefs)
+			if !ok {
+				continue
+			}
+			lo, hi := row.lo/ratio, row.hi/ratio
+			if ratio < 0 {
+				lo, hi = hi, lo
+			}
+			target := &p.rows[i]
+			target.lo, target.hi = math.Max(target.lo, lo), math.Min(target.hi, hi)
+			if Fgt(target.lo, target.hi, p.tolerance) {
+				return false, InfeasibleError{s: fmt.Sprintf("constraints %d and %d are parallel and exclude each other", i, k)}
+			}
+			row.removed = true
+			merged = true
+			break
+		}
+	}
+	return merged, nil
+}
+
+// returns the ratio r of coefficients b = r a if there is one
+func (p *presolver) parallel(a, b map[int]float64) (float64, bool) {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0, false
+	}
+	ratio := 0.
+	for j, v := range a {
+		w, ok := b[j]
+		if !ok {
+			return 0, false
+		}
+		if ratio == 0 {
+			ratio = w / v
+		}
+		if !Feq(w, ratio*v, p.tolerance*math.Max(1, math.Abs(w))) {
+			return 0, false
+		}
+	}
+	return ratio, true
+}
+
+// optimizes the presolved LP and makes the postsolved solution the starting point
+// of the simplex algorithm on the LP as built; returns false if the LP has to be
+// optimized as built instead
+func (lp *LP) optimizePresolved() bool {
+	p, err := lp.Presolve()
+	if err != nil {
+		return false
+	}
+	var solution []float64
+	if p.LP != nil {
+		p.LP.control = lp.control
+		p.LP.solve()
+		if lp.control.stopped() {
+			return true
+		}
+		if !p.LP.optimal {
+			return false
+		}
+		solution = p.LP.basicSolution()
+	}
+	if !lp.crash(p.Postsolve(solution)) {
+		lp.reset()
+		lp.snapshot()
+		return false
+	}
+	return true
+}
+
+// makes x, a solution of every variable of the LP as built, the basic solution of
+// the tableau and continues the simplex algorithm from there. The variables
+// strictly between their bounds become basic. Returns false if x does not give
+// a feasible basis.
+func (lp *LP) crash(x []float64) bool {
+	if !lp.applyBounds() {
+		return false
+	}
+	lp.negateObjectiveFunction()
+	lp.setPhase(PHASEII)
+	tol := lp.GetTolerances()
+	n := lp.Width() - 1
+	lp.basis = make([]int, lp.NumConstraints())
+	for r := range lp.basis {
+		lp.basis[r] = -1
+	}
+
+	// values of the variables of the tableau; those at their upper bound are
+	// complemented to 0
+	t := append([]float64{}, x...)
+	b := lp.bounded
+	if b != nil {
+		for j := range t {
+			t[j] = (x[j] - b.offset[j]) * b.direction[j]
+			if !b.free[j] && Fgt(t[j], 0, tol.PrimalFeasibility) && Feq(t[j], b.upper[j], tol.PrimalFeasibility) {
+				lp.complement(j)
+				t[j] = 0
+			}
+		}
+	}
+	inBasis := make([]bool, n)
+	enter := func(col int) bool {
+		row, high := 0, tol.Pivot
+		for r, v := range lp.basis {
+			if a := math.Abs(lp.tableau[r+1][col]); v < 0 && a > high {
+				row, high = r+1, a
+			}
+		}
+		if row == 0 {
+			return false
+		}
+		lp.pivot(row, col)
+		inBasis[col-1] = true
+		return true
+	}
+	for j, v := range t {
+		free := b != nil && b.free[j]
+		if (free && !Feq(v, 0, tol.PrimalFeasibility) || Fgt(v, 0, tol.PrimalFeasibility)) && !enter(j+1) {
+			return false
+		}
+	}
+	// complete the basis with variables at 0
+	for r := range lp.basis {
+		if lp.basis[r] >= 0 {
+			continue
+		}
+		for j := 0; j < n && lp.basis[r] < 0; j++ {
+			if !inBasis[j] && !Feq(lp.tableau[r+1][j+1], 0, tol.Pivot) {
+				lp.pivot(r+1, j+1)
+				inBasis[j] = true
+			}
+		}
+		if lp.basis[r] < 0 && !Feq(lp.tableau[r+1][0], 0, tol.PrimalFeasibility) {
+			return false
+		}
+	}
+	for r, v := range lp.basis {
+		value := lp.tableau[r+1][0]
+		if v < 0 || b != nil && b.free[v] {
+			continue
+		}
+		if Flt(value, 0, tol.PrimalFeasibility) || b != nil && Fgt(value, b.upper[v], tol.PrimalFeasibility) {
+			return false
+		}
+	}
+	lp.priceOut()
+	lp.feasibilityKnown = true
+	lp.feasible = true
+	lp.simplex()
+	return true
+}
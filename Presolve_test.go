@@ -0,0 +1,194 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// an LP that presolve reduces to nothing
+func reducibleLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 3, 2, 1, 0, -1)
+	lp.AddConstraintGeq(10, 1, 1, 1)
+	lp.AddConstraintGeq(16, 2, 2, 2)      // parallel to the first constraint
+	lp.AddConstraintEq(2, 0, 0, 1)        // fixes x3
+	lp.AddConstraintGeq(5)                // empty
+	lp.AddConstraintGeq(6, 1, 0, 0, 0, 1) // x5 is dominated by its lower bound
+	lp.SetBounds(1, 1, 1)
+	return lp
+}
+
+func TestLP_Presolve(t *testing.T) {
+	lp := reducibleLP()
+	p, err := lp.Presolve()
+	assert.NoError(t, err)
+	assert.Nil(t, p.LP)
+	assert.Empty(t, p.Columns)
+	assert.Empty(t, p.Rows)
+	x := p.Postsolve(nil)
+	assert.InDeltaSlice(t, []float64{5, 1, 2, 0, 0, 2, 0, 5, 1}, x, EPSILON)
+
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 19, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, x, result.Solution, EPSILON)
+
+	// the LP itself is not modified
+	assert.Len(t, lp.original, 6)
+	lp.SetPresolve(true)
+	presolved := lp.Optimize()
+	assert.Equal(t, OPTIMAL, presolved.Status)
+	assert.InDelta(t, 19, presolved.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, x, presolved.Solution, EPSILON)
+	assert.InDeltaSlice(t, result.Duals, presolved.Duals, EPSILON)
+}
+
+func TestLP_Presolve_Reduced(t *testing.T) {
+	lp := sensitivityLP()
+	lp.AddConstraintGeq(36, 6, 4) // parallel to the last constraint
+	lp.AddConstraintLeq(1, 0, 0, 0, 0, 0, 0, 1)
+	p, err := lp.Presolve()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, p.Columns)
+	assert.Equal(t, []int{2}, p.Rows)
+	// x1 <= 4 and 2x2 <= 12 became bounds
+	for j, expected := range [][]float64{{0, 4}, {0, 6}} {
+		lower, upper := p.LP.Bounds(j)
+		assert.Equal(t, expected, []float64{lower, upper})
+	}
+	assert.Equal(t, 1, p.LP.NumConstraints())
+	assert.Equal(t, OPTIMAL, p.LP.Optimize().Status)
+	sol, _ := p.LP.Solution()
+	x := p.Postsolve(sol)
+	assert.InDeltaSlice(t, []float64{2, 6, 2, 0, 0, 0, 1, 0}, x, EPSILON)
+
+	lp.SetPresolve(true)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 36, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, x, result.Solution, EPSILON)
+
+	// warm start from the postsolved basis
+	lp.AddConstraintGeq(1, 1)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+}
+
+func TestLP_Presolve_Errors(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.AddConstraintLeq(2, 1)
+	_, err := lp.Presolve()
+	assert.IsType(t, InfeasibleError{}, err)
+	lp.SetPresolve(true)
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+	// the LP as built gives the certificate
+	_, err = lp.Solution()
+	assertFarkas(t, lp, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1, 1)
+	_, err = lp.Presolve()
+	if assert.IsType(t, UnboundedError{}, err) {
+		assert.Equal(t, 1, err.(UnboundedError).Entering)
+	}
+	lp.SetPresolve(true)
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+}
+
+func TestLP_Presolve_Minimize(t *testing.T) {
+	// min x1 + x2 - x3 st. x1 >= 1, x3 <= 2: every variable is dominated
+	lp := NewLP()
+	lp.SetObjectiveFunction(MINIMIZE, 0, 1, 1, -1)
+	lp.AddConstraintLeq(1, 1)
+	lp.AddConstraintGeq(2, 0, 0, 1)
+	p, err := lp.Presolve()
+	assert.NoError(t, err)
+	assert.Nil(t, p.LP)
+	assert.InDeltaSlice(t, []float64{1, 0, 2, 0, 0}, p.Postsolve(nil), EPSILON)
+	lp.SetPresolve(true)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, -1, result.ObjectiveValue, EPSILON)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MINIMIZE, 0, -1, 1)
+	lp.AddConstraintLeq(1, 1)
+	_, err = lp.Presolve()
+	if assert.IsType(t, UnboundedError{}, err) {
+		assert.Equal(t, 0, err.(UnboundedError).Entering)
+	}
+}
+
+func TestLP_Presolve_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	for test := 0; test < 200; test++ {
+		n, m := 2+r.Intn(4), 1+r.Intn(5)
+		lp := NewLP()
+		objective := make([]float64, n)
+		for j := range objective {
+			objective[j] = float64(r.Intn(9) - 3)
+		}
+		objectiveType := MAXIMIZE
+		if test%2 == 1 {
+			objectiveType = MINIMIZE
+		}
+		lp.SetObjectiveFunction(objectiveType, 0, objective...)
+		var previous []float64
+		for i := 0; i < m; i++ {
+			coefs := make([]float64, n)
+			switch r.Intn(4) {
+			case 0: // singleton
+				coefs[r.Intn(n)] = float64(1 + r.Intn(3))
+			case 1: // parallel to the previous constraint
+				if previous != nil {
+					ScalarVectorMultiply(float64(1+r.Intn(3)), append(coefs[:0], previous...))
+					break
+				}
+				fallthrough
+			default:
+				for j := range coefs {
+					coefs[j] = float64(r.Intn(5) - 1)
+				}
+			}
+			previous = coefs
+			b := float64(1 + r.Intn(20))
+			switch r.Intn(4) {
+			case 0:
+				lp.AddConstraintEq(b, coefs...)
+			case 1:
+				lp.AddConstraintLeq(b/4, coefs...)
+			case 2:
+				lp.AddConstraintRange(b/2, b, coefs...)
+			default:
+				lp.AddConstraintGeq(b, coefs...)
+			}
+		}
+		for j := 0; j < n; j++ {
+			switch r.Intn(5) {
+			case 0:
+				lp.SetBounds(j, 1, 1)
+			case 1:
+				lp.SetBounds(j, -2, 3)
+			case 2:
+				lp.SetBounds(j, 0, 5)
+			}
+		}
+		expected := lp.Copy().Optimize()
+		lp.SetPresolve(true)
+		result := lp.Optimize()
+		if expected.Status == INFEASIBLE || expected.Status == UNBOUNDED {
+			assert.Equal(t, expected.Status, result.Status, "test %d", test)
+			continue
+		}
+		if assert.Equal(t, OPTIMAL, result.Status, "test %d", test) {
+			assert.InDelta(t, expected.ObjectiveValue, result.ObjectiveValue, 1e-6, "test %d", test)
+			assert.True(t, lp.satisfies(result.Solution))
+			assert.False(t, math.IsNaN(result.ObjectiveValue))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sago
+
+import (
+	"log/slog"
+	"math"
+)
+
+const (
+	//PHASEI looks for a feasible basis by minimizing the sum of the artificial variables
+	PHASEI = iota + 1
+	//PHASEII improves the objective function from a feasible basis
+	PHASEII
+	//DUALSIMPLEX restores feasibility after constraints were added to an optimal LP
+	DUALSIMPLEX
+)
+
+//Progress describes an iteration of the simplex algorithm
+type Progress struct {
+	Phase     int // PHASEI, PHASEII or DUALSIMPLEX
+	Iteration int // number of pivots so far, counted across phases
+	//Entering and Leaving are the indices of the variables that entered and
+	//left the basis; indices past the variables of the LP are the artificial
+	//variables of phase I. Leaving is -1 if the entering variable moved to its
+	//upper bound instead.
+	Entering int
+	Leaving  int
+	//ObjectiveValue is the objective value of the basis; in phase I, that of
+	//the auxiliary LP, which is -Infeasibility
+	ObjectiveValue float64
+	//Infeasibility is the sum of the violations of the constraints by the basis
+	Infeasibility float64
+}
+
+//Callback is called by Optimize after every pivot; returning false stops the
+//simplex algorithm with status INTERRUPTED
+type Callback func(Progress) bool
+
+//SetCallback sets the function called after every pivot of Optimize, or
+//removes it if callback is nil
+func (lp *LP) SetCallback(callback Callback) {
+	lp.callback = callback
+}
+
+//SetLogger makes Optimize log the size of the LP, every pivot and the result
+//to logger, or stops logging if logger is nil
+func (lp *LP) SetLogger(logger *slog.Logger) {
+	lp.logger = logger
+}
+
+// the callback of a run of Optimize, which logs the pivots if there is a logger
+func (lp *LP) progressCallback() Callback {
+	callback, logger := lp.callback, lp.logger
+	if logger == nil {
+		return callback
+	}
+	return func(p Progress) bool {
+		logger.Info("iteration",
+			slog.String("phase", phaseName(p.Phase)),
+			slog.Int("iteration", p.Iteration),
+			slog.Int("entering", p.Entering),
+			slog.Int("leaving", p.Leaving),
+			slog.Float64("objective", p.ObjectiveValue),
+			slog.Float64("infeasibility", p.Infeasibility))
+		return callback == nil || callback(p)
+	}
+}
+
+func phaseName(phase int) string {
+	switch phase {
+	case PHASEI:
+		return "phase I"
+	case PHASEII:
+		return "phase II"
+	case DUALSIMPLEX:
+		return "dual simplex"
+	}
+	return "unknown"
+}
+
+// reports a pivot of the tableau engine
+func (lp *LP) reportIteration(entering, leaving int) {
+	c := lp.control
+	if !c.reporting() {
+		return
+	}
+	p := Progress{Entering: entering, Leaving: leaving, ObjectiveValue: lp.ObjectiveValue()}
+	switch c.phase {
+	case PHASEI:
+		p.Infeasibility = math.Max(0, -lp.tableau[0][0])
+	case DUALSIMPLEX:
+		for i, row := range lp.ListConstraints() {
+			v := lp.basis[i]
+			switch {
+			case lp.bounded != nil && v >= 0 && lp.bounded.free[v]:
+			case row[0] < 0:
+				p.Infeasibility -= row[0]
+			case lp.bounded != nil && v >= 0 && row[0] > lp.bounded.upper[v]:
+				p.Infeasibility += row[0] - lp.bounded.upper[v]
+			}
+		}
+	}
+	c.report(p)
+}
+
+// reports a pivot of the revised engine
+func (rs *revisedSimplex) report(entering, leaving int) {
+	c := rs.lp.control
+	if !c.reporting() {
+		return
+	}
+	p := Progress{Entering: entering, Leaving: leaving, ObjectiveValue: rs.objectiveValue()}
+	if rs.phaseI {
+		p.Infeasibility = math.Max(0, -p.ObjectiveValue)
+	} else {
+		p.ObjectiveValue = rs.lp.sense()*p.ObjectiveValue + rs.lp.original[0][0]
+	}
+	c.report(p)
+}
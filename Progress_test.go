@@ -0,0 +1,88 @@
+package sago
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLP_SetCallback(t *testing.T) {
+	for _, engine := range []int{TABLEAU, REVISED} {
+		lp := sensitivityLP()
+		lp.AddConstraintLeq(1, 1, 1)
+		lp.SetEngine(engine)
+		var progress []Progress
+		lp.SetCallback(func(p Progress) bool {
+			progress = append(progress, p)
+			return true
+		})
+		result := lp.Optimize()
+		assert.Equal(t, OPTIMAL, result.Status)
+		if assert.Len(t, progress, result.Iterations) {
+			for i, p := range progress {
+				assert.Equal(t, i+1, p.Iteration)
+				assert.GreaterOrEqual(t, p.Entering, 0)
+				assert.GreaterOrEqual(t, p.Infeasibility, 0.)
+			}
+			assert.Equal(t, PHASEI, progress[0].Phase)
+			last := progress[len(progress)-1]
+			assert.Equal(t, PHASEII, last.Phase)
+			assert.InDelta(t, 36, last.ObjectiveValue, EPSILON)
+			assert.Zero(t, last.Infeasibility)
+		}
+
+		// warm start with the dual simplex algorithm
+		progress = nil
+		lp.AddConstraintGeq(1, 1)
+		assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+		if assert.NotEmpty(t, progress) {
+			assert.Equal(t, DUALSIMPLEX, progress[0].Phase)
+			assert.Equal(t, 1, progress[0].Iteration)
+			assert.Zero(t, progress[len(progress)-1].Infeasibility)
+		}
+		assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+	}
+}
+
+func TestLP_SetCallback_Interrupted(t *testing.T) {
+	lp := bealeLP()
+	calls := 0
+	lp.SetCallback(func(p Progress) bool {
+		calls++
+		return p.Iteration < 10
+	})
+	result := lp.Optimize()
+	assert.Equal(t, INTERRUPTED, result.Status)
+	assert.True(t, result.Status.Limit())
+	assert.Equal(t, 10, calls)
+	assert.Equal(t, 10, result.Iterations)
+	assert.False(t, lp.Solved())
+
+	// the callback can be removed
+	lp.SetCallback(nil)
+	lp.SetPivotRule(Bland{})
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	assert.Equal(t, 10, calls)
+}
+
+func TestLP_SetLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lp := sensitivityLP()
+	lp.SetLogger(slog.New(slog.NewTextHandler(buf, nil)))
+	lp.SetCallback(func(Progress) bool { return true })
+	result := lp.Optimize()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, result.Iterations+2) {
+		assert.Contains(t, lines[0], `msg="simplex started" constraints=3 variables=5`)
+		assert.Contains(t, lines[1], `msg=iteration phase="phase I" iteration=1`)
+		assert.Contains(t, lines[len(lines)-1], `msg="simplex finished" status=optimal`)
+		assert.Contains(t, lines[len(lines)-1], "objective=36")
+	}
+
+	buf.Reset()
+	lp.SetLogger(nil)
+	lp.Optimize()
+	assert.Empty(t, buf.String())
+}
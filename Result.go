@@ -0,0 +1,155 @@
+package sago
+
+import (
+	"math"
+	"time"
+)
+
+//Status tells how Optimize ended
+type Status int
+
+const (
+	//OPTIMAL the LP has an optimal solution
+	OPTIMAL Status = iota
+	//INFEASIBLE the LP has no feasible solution
+	INFEASIBLE
+	//UNBOUNDED the objective function of the LP improves without limit
+	UNBOUNDED
+	//ITERATIONLIMIT OptimizeContext stopped after the maximum number of iterations
+	ITERATIONLIMIT
+	//TIMELIMIT OptimizeContext stopped at the time limit or the deadline of the context
+	TIMELIMIT
+	//CANCELED the context of OptimizeContext was canceled
+	CANCELED
+	//INTERRUPTED the callback set with SetCallback stopped the simplex algorithm
+	INTERRUPTED
+	//NUMERICAL the solution found violates the LP by more than the tolerance, or
+	//the EXACT engine found no basis of an LP with bounds for its solution
+	NUMERICAL
+)
+
+// largest violation of a constraint or bound, relative to 1 + |b|, accepted
+// in an optimal solution
+const residualTolerance = 1e-6
+
+//Result is the outcome of Optimize
+type Result struct {
+	Status Status
+	//ObjectiveValue is the objective value of Solution
+	ObjectiveValue float64
+	//Solution is the value of each variable in the final basis: the optimal
+	//solution, or the last feasible basis reached before a limit; nil if there is
+	//none
+	Solution []float64
+	//Duals is the dual value of each constraint of an optimal LP, i.e. the
+	//change in objective value per unit increase of its b entry; nil if the LP
+	//has bounds
+	Duals []float64
+	//Iterations is the number of simplex pivots
+	Iterations int
+	//Time is the wall-clock time spent
+	Time time.Duration
+}
+
+//Limit returns true if the status tells that OptimizeContext reached a limit or
+//the callback stopped it
+func (s Status) Limit() bool {
+	return s == ITERATIONLIMIT || s == TIMELIMIT || s == CANCELED || s == INTERRUPTED
+}
+
+func (s Status) String() string {
+	switch s {
+	case OPTIMAL:
+		return "optimal"
+	case INFEASIBLE:
+		return "infeasible"
+	case UNBOUNDED:
+		return "unbounded"
+	case ITERATIONLIMIT:
+		return "iteration limit"
+	case TIMELIMIT:
+		return "time limit"
+	case CANCELED:
+		return "canceled"
+	case INTERRUPTED:
+		return "interrupted"
+	case NUMERICAL:
+		return "numerical trouble"
+	}
+	return "unknown"
+}
+
+// describes the state of the LP after the simplex algorithm ran with limits
+func (lp *LP) result(control *solveControl, elapsed time.Duration) *Result {
+	r := &Result{Iterations: control.iterations, Time: elapsed}
+	switch {
+	case control.reached:
+		r.Status = control.status
+		if lp.feasibilityKnown && lp.feasible && lp.basis != nil {
+			r.Solution = lp.basicSolution()
+			r.ObjectiveValue = lp.ObjectiveValue()
+		}
+		return r
+	case !lp.feasible:
+		r.Status = INFEASIBLE
+		return r
+	case lp.unbounded:
+		r.Status = UNBOUNDED
+		return r
+	}
+	r.Solution = lp.basicSolution()
+	r.ObjectiveValue = lp.ObjectiveValue()
+	if !lp.satisfies(r.Solution) {
+		r.Status = NUMERICAL
+		return r
+	}
+	r.Status = OPTIMAL
+	if lp.bounded == nil {
+		if lp.revised != nil {
+			// the duals of the objective function times the sense
+			r.Duals = ScalarVectorMultiply(lp.sense(), lp.revised.duals())
+		} else if basis, inverse, err := lp.basisInverse(); err == nil {
+			r.Duals = lp.duals(basis, inverse)
+		}
+		if r.Duals != nil {
+			for i := range r.Duals {
+				r.Duals[i] *= lp.rowInfo(i).sign
+			}
+		}
+	}
+	return r
+}
+
+// true if the values are finite and satisfy the LP as built and the bounds of
+// the variables within residualTolerance
+func (lp *LP) satisfies(sol []float64) bool {
+	for j, x := range sol {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+		lower, upper := lp.Bounds(j)
+		if x < lower-residualTolerance*(1+math.Abs(lower)) || x > upper+residualTolerance*(1+math.Abs(upper)) {
+			return false
+		}
+	}
+	if s := lp.sparse; s != nil {
+		for i, b := range s.b {
+			if math.Abs(s.rows.line(i).dot(sol)-b) > residualTolerance*(1+math.Abs(b)) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, row := range lp.original[1:] {
+		lhs := 0.0
+		for j, x := range sol {
+			if j+1 < len(row) {
+				lhs += row[j+1] * x
+			}
+		}
+		if math.Abs(lhs-row[0]) > residualTolerance*(1+math.Abs(row[0])) {
+			return false
+		}
+	}
+	return true
+}
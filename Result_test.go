@@ -0,0 +1,68 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLP_OptimizeResult(t *testing.T) {
+	lp := sensitivityLP()
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.False(t, result.Status.Limit())
+	assert.InDelta(t, 36, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, []float64{2, 6, 2, 0, 0}, result.Solution, EPSILON)
+	assert.Greater(t, result.Iterations, 0)
+	assert.Greater(t, int64(result.Time), int64(0))
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, report.ShadowPrices, result.Duals, EPSILON)
+
+	// a warm start counts the pivots of the dual simplex algorithm
+	lp.AddConstraintGeq(7, 1, 1)
+	result = lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 33, result.ObjectiveValue, EPSILON)
+	assert.Equal(t, 1, result.Iterations)
+
+	lp.AddConstraintLeq(8, 1, 1)
+	result = lp.Optimize()
+	assert.Equal(t, INFEASIBLE, result.Status)
+	assert.Nil(t, result.Solution)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(2, 1, -1)
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+
+	// LPs with bounds have no duals
+	lp = sensitivityLP()
+	lp.SetBounds(0, 0, 1)
+	result = lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 33, result.ObjectiveValue, EPSILON)
+	assert.Nil(t, result.Duals)
+}
+
+func TestLP_satisfies(t *testing.T) {
+	lp := sensitivityLP()
+	lp.SetBounds(1, 0, 6)
+	result := lp.Optimize()
+	assert.True(t, lp.satisfies(result.Solution))
+	sol := append([]float64{}, result.Solution...)
+	sol[0] += 1e-3
+	assert.False(t, lp.satisfies(sol))
+	sol = append([]float64{}, result.Solution...)
+	sol[1] = 6.1
+	sol[3] = -0.2
+	assert.False(t, lp.satisfies(sol))
+	sol[0] = math.NaN()
+	assert.False(t, lp.satisfies(sol))
+}
+
+func TestStatus_String(t *testing.T) {
+	assert.Equal(t, "optimal", OPTIMAL.String())
+	assert.Equal(t, "time limit", TIMELIMIT.String())
+	assert.Equal(t, "unknown", Status(-1).String())
+}
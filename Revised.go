@@ -0,0 +1,462 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// number of eta updates of the SPARSE engine before the basis is refactorized
+const refactorInterval = 32
+
+// number of Forrest-Tomlin updates of the REVISED engine before the basis is
+// refactorized, which bounds the row etas they accumulate
+const luRefactorInterval = 100
+
+// column p of an elementary matrix E with B'^-1 = E B^-1: pivot is its entry p
+// and column holds its other nonzero entries
+type eta struct {
+	p      int
+	pivot  float64
+	column sparseVector
+}
+
+// state of the revised simplex algorithm on an LP of m constraints and n
+// variables. Variables n, ..., n+m-1 are the artificial variables of phase I.
+// The SPARSE engine keeps B^-1 as a product of eta matrices, refactorized every
+// refactorInterval pivots. The REVISED engine keeps an LU factorization of B
+// whose factors are updated in place at every pivot with the Forrest-Tomlin
+// update, and refactorizes B every luRefactorInterval pivots or when an update
+// is numerically singular.
+type revisedSimplex struct {
+	lp       *LP
+	m, n     int
+	columns  *sparseMatrix // columns of A (CSC)
+	b        []float64
+	c        []float64 // objective of the current phase, including artificials
+	phaseI   bool
+	basis    []int
+	inBasis  []bool
+	xB       []float64 // values of the basic variables
+	sparse   bool      // product form of the inverse instead of LU
+	lu       *luFactorization
+	etas     []eta
+	updates  int // eta updates since the last refactorization
+	entering int // variable whose column failed the ratio test
+}
+
+func newRevisedSimplex(lp *LP) *revisedSimplex {
+	m := lp.NumConstraints()
+	n := lp.Width() - 1
+	rs := &revisedSimplex{
+		lp:      lp,
+		m:       m,
+		n:       n,
+		b:       make([]float64, m),
+		c:       make([]float64, n+m),
+		basis:   make([]int, m),
+		inBasis: make([]bool, n+m),
+		sparse:  lp.engine == SPARSE,
+	}
+	if lp.sparse != nil {
+		copy(rs.b, lp.sparse.b)
+		rs.columns = lp.sparse.rows.transpose(n)
+	} else {
+		rows := &sparseMatrix{}
+		for i, row := range lp.tableau[1:] {
+			rs.b[i] = row[0]
+			rows.appendLine(newSparseVector(row[1:]))
+		}
+		rs.columns = rows.transpose(n)
+	}
+	for i := range rs.basis {
+		rs.basis[i] = n + i
+		rs.inBasis[n+i] = true
+	}
+	return rs
+}
+
+// executes the simplex algorithm on the LP with the revised simplex engine
+func (lp *LP) revisedSimplex() error {
+	rs := newRevisedSimplex(lp)
+
+	// Phase I: maximize -(sum of artificial variables)
+	rs.phaseI = true
+	lp.control.setPhase(PHASEI)
+	for i := 0; i < rs.m; i++ {
+		rs.c[rs.n+i] = -1
+	}
+	if err := rs.refactor(); err != nil {
+		return err
+	}
+	if _, err := rs.simplex(); err != nil {
+		return err
+	}
+	if lp.control.stopped() {
+		return nil
+	}
+	lp.feasibilityKnown = true
+	lp.feasible = Feq(rs.objectiveValue(), 0, lp.GetTolerances().PrimalFeasibility)
+	rs.phaseI = false
+	lp.control.setPhase(PHASEII)
+	for j := range rs.c {
+		rs.c[j] = 0
+	}
+	// maximizes the objective function times the sense
+	for j, c := range lp.tableau[0][1:] {
+		rs.c[j] = lp.sense() * c
+	}
+	if !lp.feasible {
+		lp.unbounded = false
+		lp.optimal = false
+		lp.solved = true
+		return rs.writeTableau()
+	}
+
+	// Phase II
+	if err := rs.driveOutArtificials(); err != nil {
+		return err
+	}
+	unbounded, err := rs.simplex()
+	if err != nil {
+		return err
+	}
+	if lp.control.stopped() {
+		return rs.writeTableau()
+	}
+	lp.unbounded = unbounded
+	lp.optimal = !unbounded
+	lp.solved = true
+	if err := rs.writeTableau(); err != nil {
+		return err
+	}
+	if unbounded {
+		rs.setRay()
+	}
+	return nil
+}
+
+// runs simplex iterations until the current phase is optimal or unbounded
+func (rs *revisedSimplex) simplex() (bool, error) {
+	tol := rs.lp.GetTolerances()
+	for {
+		y := rs.duals()
+		col := -1
+		for j := 0; j < rs.n+rs.m && col < 0; j++ {
+			if rs.eligible(j) && Fgt(rs.reducedCost(j, y), 0, tol.DualFeasibility) {
+				col = j
+			}
+		}
+		if col < 0 {
+			return false, nil
+		}
+		if !rs.lp.control.next() {
+			return false, nil
+		}
+		w := rs.ftran(rs.column(col).dense(rs.m))
+		row := rs.ratioTest(w)
+		if row < 0 {
+			rs.entering = col
+			return true, nil
+		}
+		leaving := rs.basis[row]
+		if err := rs.pivot(row, col, w); err != nil {
+			return false, err
+		}
+		rs.report(col, leaving)
+	}
+}
+
+// true if variable j may enter the basis
+func (rs *revisedSimplex) eligible(j int) bool {
+	return !rs.inBasis[j] && (j < rs.n || rs.phaseI)
+}
+
+// c_j - yA_j
+func (rs *revisedSimplex) reducedCost(j int, y []float64) float64 {
+	return rs.c[j] - rs.column(j).dot(y)
+}
+
+// returns the row of the basic variable with the lowest ratio xB_i / w_i, or -1
+func (rs *revisedSimplex) ratioTest(w []float64) int {
+	row := -1
+	var low float64
+	tol := rs.lp.GetTolerances()
+	for i, wi := range w {
+		if Fle(wi, 0, tol.Pivot) {
+			continue
+		}
+		ratio := rs.xB[i] / wi
+		if Fle(0, ratio, tol.Zero) && (row < 0 || Flt(ratio, low, tol.Zero)) {
+			row, low = i, ratio
+		}
+	}
+	return row
+}
+
+// replaces the basic variable of row by col, where w = B^-1 A_col
+func (rs *revisedSimplex) pivot(row, col int, w []float64) error {
+	theta := rs.xB[row] / w[row]
+	for i := range rs.xB {
+		rs.xB[i] -= theta * w[i]
+	}
+	rs.xB[row] = theta
+	rs.inBasis[rs.basis[row]] = false
+	rs.inBasis[col] = true
+	rs.basis[row] = col
+
+	rs.updates++
+	if rs.lu != nil {
+		if rs.updates >= luRefactorInterval || rs.lu.update(row, rs.column(col).dense(rs.m), rs.lp.GetTolerances().Pivot) != nil {
+			return rs.refactor()
+		}
+		return nil
+	}
+	rs.etas = append(rs.etas, newEta(row, w))
+	if rs.updates >= refactorInterval {
+		return rs.refactor()
+	}
+	return nil
+}
+
+// the eta matrix that pivots w on its entry p
+func newEta(p int, w []float64) eta {
+	e := eta{p: p, pivot: 1 / w[p]}
+	for i, wi := range w {
+		if i != p && wi != 0 {
+			e.column.index = append(e.column.index, i)
+			e.column.value = append(e.column.value, -wi/w[p])
+		}
+	}
+	return e
+}
+
+// pivots basic artificial variables out of the basis where the constraint has
+// a nonzero original variable
+func (rs *revisedSimplex) driveOutArtificials() error {
+	for r := range rs.basis {
+		if rs.basis[r] < rs.n {
+			continue
+		}
+		unit := make([]float64, rs.m)
+		unit[r] = 1
+		rho := rs.btran(unit)
+		for j := 0; j < rs.n; j++ {
+			if !rs.eligible(j) {
+				continue
+			}
+			alpha := rs.column(j).dot(rho)
+			if !Feq(alpha, 0, rs.lp.GetTolerances().Pivot) {
+				if err := rs.pivot(r, j, rs.ftran(rs.column(j).dense(rs.m))); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// factorizes the current basis, discards the eta updates and recomputes xB
+func (rs *revisedSimplex) refactor() error {
+	rs.etas = nil
+	rs.updates = 0
+	factorize := rs.factorizeLU
+	if rs.sparse {
+		factorize = rs.factorizeEtas
+	}
+	if err := factorize(); err != nil {
+		return err
+	}
+	rs.xB = rs.ftran(rs.b)
+	return nil
+}
+
+func (rs *revisedSimplex) factorizeLU() error {
+	B := make([][]float64, rs.m)
+	for i := range B {
+		B[i] = make([]float64, rs.m)
+	}
+	for k, v := range rs.basis {
+		column := rs.column(v)
+		for e, i := range column.index {
+			B[i][k] = column.value[e]
+		}
+	}
+	lu, err := factorize(B, rs.lp.GetTolerances().Pivot)
+	if err != nil {
+		return err
+	}
+	rs.lu = lu
+	return nil
+}
+
+// rebuilds B^-1 as a product of eta matrices, starting from the identity of the
+// artificial variables: each basic variable of the LP, sparsest column first,
+// replaces the artificial variable of the free row where its transformed column
+// has the largest entry. The basic variables may change rows.
+func (rs *revisedSimplex) factorizeEtas() error {
+	rs.lu = nil
+	basis := make([]int, rs.m)
+	taken := make([]bool, rs.m)
+	var columns []int
+	for r, v := range rs.basis {
+		basis[r] = rs.n + r
+		if v >= rs.n {
+			taken[v-rs.n] = true
+		} else {
+			columns = append(columns, v)
+		}
+	}
+	sort.SliceStable(columns, func(a, b int) bool {
+		return len(rs.column(columns[a]).index) < len(rs.column(columns[b]).index)
+	})
+	tol := rs.lp.GetTolerances().Pivot
+	for _, v := range columns {
+		w := rs.ftran(rs.column(v).dense(rs.m))
+		row := -1
+		for i, wi := range w {
+			if !taken[i] && (row < 0 || math.Abs(wi) > math.Abs(w[row])) {
+				row = i
+			}
+		}
+		if row < 0 || Feq(w[row], 0, tol) {
+			return InvalidInputError{s: fmt.Sprintf("basis is singular at column %d", v)}
+		}
+		taken[row] = true
+		basis[row] = v
+		rs.etas = append(rs.etas, newEta(row, w))
+	}
+	rs.basis = basis
+	return nil
+}
+
+// solves Bx = r
+func (rs *revisedSimplex) ftran(r []float64) []float64 {
+	var x []float64
+	if rs.lu != nil {
+		x = rs.lu.solve(r)
+	} else {
+		x = append(x, r...)
+	}
+	for _, e := range rs.etas {
+		xp := x[e.p]
+		if xp == 0 {
+			continue
+		}
+		for k, i := range e.column.index {
+			x[i] += e.column.value[k] * xp
+		}
+		x[e.p] = e.pivot * xp
+	}
+	return x
+}
+
+// solves yB = c
+func (rs *revisedSimplex) btran(c []float64) []float64 {
+	c = append([]float64{}, c...)
+	for k := len(rs.etas) - 1; k >= 0; k-- {
+		e := rs.etas[k]
+		c[e.p] = c[e.p]*e.pivot + e.column.dot(c)
+	}
+	if rs.lu == nil {
+		return c
+	}
+	return rs.lu.solveTranspose(c)
+}
+
+// y = c_B B^-1
+func (rs *revisedSimplex) duals() []float64 {
+	cB := make([]float64, rs.m)
+	for i, v := range rs.basis {
+		cB[i] = rs.c[v]
+	}
+	return rs.btran(cB)
+}
+
+func (rs *revisedSimplex) column(j int) sparseVector {
+	if j < rs.n {
+		return rs.columns.line(j)
+	}
+	return sparseVector{index: []int{j - rs.n}, value: []float64{1}}
+}
+
+func (rs *revisedSimplex) objectiveValue() float64 {
+	z := 0.0
+	for i, v := range rs.basis {
+		z += rs.c[v] * rs.xB[i]
+	}
+	return z
+}
+
+// replaces the tableau of the LP by B^-1 (b | A) with the objective function
+// in terms of the nonbasic variables, as left behind by the tableau engine.
+// The objective function and the basis are written now; B^-1 (b | A), which
+// takes an ftran per column, is written by densify when the rows are read,
+// e.g. by Sensitivity or a warm start, and not at all if only the solution and
+// the duals are. Scaled LPs have their rows written now for unscale.
+func (rs *revisedSimplex) writeTableau() error {
+	if err := rs.refactor(); err != nil {
+		return err
+	}
+	lp := rs.lp
+	y := rs.duals()
+	objective := append([]float64{}, lp.tableau[0]...)
+	objective[0] = lp.sense()*lp.original[0][0] + rs.objectiveValue()
+	for j := 0; j < rs.n; j++ {
+		objective[j+1] = -rs.reducedCost(j, y)
+	}
+	lp.tableau[0] = objective
+	lp.basis = make([]int, rs.m)
+	for r, v := range rs.basis {
+		lp.basis[r] = v
+		if v >= rs.n {
+			lp.basis[r] = -1
+		}
+	}
+	if lp.sparse != nil || lp.scaling == NOSCALING {
+		lp.revised = rs
+		return nil
+	}
+	rs.writeRows()
+	return nil
+}
+
+// writes B^-1 (b | A) to the constraints of the tableau
+func (rs *revisedSimplex) writeRows() {
+	lp := rs.lp
+	for r := range lp.tableau[1:] {
+		lp.tableau[r+1][0] = rs.xB[r]
+	}
+	for j := 0; j < rs.n; j++ {
+		for r, v := range rs.ftran(rs.column(j).dense(rs.m)) {
+			lp.tableau[r+1][j+1] = v
+		}
+	}
+}
+
+// records the ray along which the entering variable grows without limit: the
+// basic variables change by minus B^-1 times its column
+func (rs *revisedSimplex) setRay() {
+	lp := rs.lp
+	lp.ray = make([]float64, rs.n)
+	lp.ray[rs.entering] = 1
+	w := rs.ftran(rs.column(rs.entering).dense(rs.m))
+	for r, v := range rs.basis {
+		if v < rs.n {
+			lp.ray[v] = -w[r]
+		}
+	}
+}
+
+// a copy for the copy c of the LP
+func (rs *revisedSimplex) copy(c *LP) *revisedSimplex {
+	if rs == nil {
+		return nil
+	}
+	r := *rs
+	r.lp = c
+	r.basis = append([]int(nil), rs.basis...)
+	r.inBasis = append([]bool(nil), rs.inBasis...)
+	return &r
+}
@@ -0,0 +1,112 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// max c x st. Ax <= b with random nonnegative data; always feasible and bounded
+func randomLP(r *rand.Rand, m, n int) *LP {
+	lp := NewLP()
+	c := make([]float64, n)
+	for j := range c {
+		c[j] = float64(r.Intn(20) + 1)
+	}
+	lp.SetObjectiveFunction(MAXIMIZE, 0, c...)
+	for i := 0; i < m; i++ {
+		a := make([]float64, n)
+		for j := range a {
+			a[j] = float64(r.Intn(10) + 1)
+		}
+		lp.AddConstraintGeq(float64(r.Intn(100)+50), a...)
+	}
+	return lp
+}
+
+func TestLP_SetEngine(t *testing.T) {
+	lp := NewLP()
+	assert.Equal(t, TABLEAU, lp.GetEngine())
+	lp.SetEngine(REVISED)
+	assert.Equal(t, REVISED, lp.GetEngine())
+}
+
+func TestLP_OptimizeRevised(t *testing.T) {
+	lp := readLP("LP_feas_sef")
+	lp.SetEngine(REVISED)
+	lp.Optimize()
+	expected := tableau{
+		{10.5, 0, 0, 1.5, 2, .5, 0},
+		{1.5, 0, 1, .5, 1, -.5, 0},
+		{2.5, 1, 0, 1.5, 0, .5, 0},
+		{0.5, 0, 0, -.5, -1, -.5, 1},
+	}
+	assert.True(t, lp.solved)
+	assert.True(t, lp.optimal)
+	assert.True(t, lp.Feasible())
+	assert.Equal(t, []int{1, 0, 5}, lp.Basis())
+	sol, _ := lp.Solution()
+	assert.InDeltaSlice(t, []float64{2.5, 1.5, 0, 0, 0, .5}, sol, EPSILON)
+	// the rows of the final tableau are written when they are read
+	assert.NotNil(t, lp.revised)
+	assert.InDeltaSlice(t, expected[0], lp.tableau[0], EPSILON)
+	for i, row := range lp.ListConstraints() {
+		assert.InDeltaSlice(t, expected[i+1], row, EPSILON)
+	}
+	assert.Nil(t, lp.revised)
+
+	lp = readLP("LP_unbounded_trivial")
+	lp.SetEngine(REVISED)
+	lp.Optimize()
+	assert.True(t, lp.solved)
+	assert.False(t, lp.optimal)
+	assert.True(t, lp.unbounded)
+	assert.True(t, lp.Feasible())
+
+	lp = readLP("LP_infeas")
+	lp.SetEngine(REVISED)
+	lp.Optimize()
+	assert.True(t, lp.solved)
+	assert.False(t, lp.optimal)
+	assert.False(t, lp.Feasible())
+}
+
+func TestLP_OptimizeRevisedFixtures(t *testing.T) {
+	for _, name := range []string{"LP_feas_min", "LP_sol_0", "LP_degenerate_iteration", "LP_infeasibility_rounding_error"} {
+		expected := readLP(name)
+		expected.Optimize()
+		lp := readLP(name)
+		lp.SetEngine(REVISED)
+		lp.Optimize()
+		assert.Equal(t, expected.Feasible(), lp.Feasible(), name)
+		assert.Equal(t, expected.Bounded(), lp.Bounded(), name)
+		assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6, name)
+	}
+}
+
+func TestLP_OptimizeRevisedRefactor(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 5; k++ {
+		expected := randomLP(r, 20, 40)
+		lp := NewLP()
+		lp.tableau = expected.tableau.copy()
+		lp.width = expected.width
+		lp.SetEngine(REVISED)
+		expected.Optimize()
+		lp.Optimize()
+		assert.True(t, lp.Optimal())
+		assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6)
+
+		// the solution satisfies the constraints
+		sol, err := lp.Solution()
+		assert.NoError(t, err)
+		for _, row := range lp.original[1:] {
+			lhs := 0.0
+			for j, x := range sol {
+				assert.True(t, Fge(x, 0, 1e-9))
+				lhs += row[j+1] * x
+			}
+			assert.InDelta(t, row[0], lhs, 1e-6)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sago
+
+import "math"
+
+const (
+	//NOSCALING solves the LP as built
+	NOSCALING = iota
+	//GEOMETRIC scales the constraints and the variables by the geometric mean of
+	//the smallest and largest absolute values of their coefficients
+	GEOMETRIC
+	//EQUILIBRATION scales the constraints and then the variables so that their
+	//largest absolute coefficient is 1
+	EQUILIBRATION
+)
+
+// passes of geometric mean scaling over the rows and columns
+const geometricScalingPasses = 4
+
+// factors of a scaled LP: constraint i is multiplied by rows[i] and variable j
+// is replaced by columns[j] times the scaled variable
+type scaling struct {
+	rows    []float64
+	columns []float64
+}
+
+//GetScaling returns the scaling applied before phase I (one of { NOSCALING, GEOMETRIC, EQUILIBRATION })
+func (lp *LP) GetScaling() int {
+	return lp.scaling
+}
+
+//SetScaling sets the scaling applied before phase I (one of { NOSCALING,
+//GEOMETRIC, EQUILIBRATION }). The scaled LP is solved and the tableau unscaled
+//afterwards, so that Solution, ObjectiveValue and the dual values are those of
+//the LP as built. The EXACT engine does not scale.
+func (lp *LP) SetScaling(method int) {
+	lp.reset()
+	lp.scaling = method
+}
+
+// computes the scaling factors of the tableau, or nil if the LP is not scaled
+func (lp *LP) scaleFactors() *scaling {
+	if lp.scaling == NOSCALING || lp.engine == EXACT {
+		return nil
+	}
+	m, n := lp.NumConstraints(), lp.Width()-1
+	s := &scaling{rows: make([]float64, m), columns: make([]float64, n)}
+	for i := range s.rows {
+		s.rows[i] = 1
+	}
+	for j := range s.columns {
+		s.columns[j] = 1
+	}
+	// the smallest and largest nonzero absolute values of the scaled coefficients
+	// of constraint i, or of variable j if i < 0
+	extremes := func(i, j int) (float64, float64) {
+		low, high := math.Inf(1), 0.
+		add := func(r, c int) {
+			if a := math.Abs(lp.tableau[r+1][c+1] * s.rows[r] * s.columns[c]); a != 0 {
+				low, high = math.Min(low, a), math.Max(high, a)
+			}
+		}
+		if i >= 0 {
+			for c := 0; c < n; c++ {
+				add(i, c)
+			}
+		} else {
+			for r := 0; r < m; r++ {
+				add(r, j)
+			}
+		}
+		return low, high
+	}
+	switch lp.scaling {
+	case GEOMETRIC:
+		for pass := 0; pass < geometricScalingPasses; pass++ {
+			for i := range s.rows {
+				if low, high := extremes(i, -1); high > 0 {
+					s.rows[i] *= powerOfTwo(1 / math.Sqrt(low*high))
+				}
+			}
+			for j := range s.columns {
+				if low, high := extremes(-1, j); high > 0 {
+					s.columns[j] *= powerOfTwo(1 / math.Sqrt(low*high))
+				}
+			}
+		}
+	case EQUILIBRATION:
+		for i := range s.rows {
+			if _, high := extremes(i, -1); high > 0 {
+				s.rows[i] = powerOfTwo(1 / high)
+			}
+		}
+		for j := range s.columns {
+			if _, high := extremes(-1, j); high > 0 {
+				s.columns[j] = powerOfTwo(1 / high)
+			}
+		}
+	}
+	return s
+}
+
+// replaces the constraints and variables of the tableau by scaled ones
+func (lp *LP) scale(s *scaling) {
+	if s == nil {
+		return
+	}
+	s.apply(lp.tableau)
+	if lp.bounded != nil {
+		for j, f := range s.columns {
+			lp.bounded.offset[j] /= f
+			lp.bounded.upper[j] /= f
+		}
+	}
+}
+
+func (s *scaling) apply(t tableau) {
+	for j, f := range s.columns {
+		t[0][j+1] *= f
+	}
+	for i, row := range t[1:] {
+		row[0] *= s.rows[i]
+		for j, f := range s.columns {
+			row[j+1] *= s.rows[i] * f
+		}
+	}
+}
+
+// undoes the scaling of the tableau, keeping its basis: the columns are divided
+// by their factors, which leaves the constraints equivalent to those of the LP
+// as built, and each constraint is multiplied by the factor of its basic
+// variable to make its coefficient 1 again
+func (lp *LP) unscale(s *scaling) {
+	if s == nil {
+		return
+	}
+	t := lp.tableau
+	for j, f := range s.columns {
+		t[0][j+1] /= f
+	}
+	for i, row := range t[1:] {
+		for j, f := range s.columns {
+			row[j+1] /= f
+		}
+		if i < len(lp.basis) && lp.basis[i] >= 0 && lp.basis[i] < len(s.columns) {
+			ScalarVectorMultiply(s.columns[lp.basis[i]], row)
+		}
+	}
+	if lp.bounded != nil {
+		for j, f := range s.columns {
+			lp.bounded.offset[j] *= f
+			lp.bounded.upper[j] *= f
+		}
+	}
+	for j := range lp.ray {
+		lp.ray[j] *= s.columns[j]
+	}
+	for i := range lp.farkas {
+		lp.farkas[i] *= s.rows[i]
+	}
+}
+
+// the power of 2 nearest to f, which scales without rounding errors
+func powerOfTwo(f float64) float64 {
+	return math.Exp2(math.Round(math.Log2(f)))
+}
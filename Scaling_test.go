@@ -0,0 +1,132 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sensitivityLP with coefficients from 1e-4 to 1e6
+func badlyScaledLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 3e-4, 5e2)
+	lp.AddConstraintGeq(4, 1e-4, 0)
+	lp.AddConstraintGeq(12e6, 0, 2e8)
+	lp.AddConstraintGeq(18e2, 3e-2, 2e4)
+	return lp
+}
+
+func TestLP_SetScaling(t *testing.T) {
+	// phase I misjudges the LP as built
+	assert.Equal(t, INFEASIBLE, badlyScaledLP().Optimize().Status)
+
+	for _, scaling := range []int{GEOMETRIC, EQUILIBRATION} {
+		for _, engine := range []int{TABLEAU, REVISED} {
+			lp := badlyScaledLP()
+			lp.SetScaling(scaling)
+			lp.SetEngine(engine)
+			assert.Equal(t, scaling, lp.GetScaling())
+			result := lp.Optimize()
+			assert.Equal(t, OPTIMAL, result.Status)
+			assert.InDelta(t, 36, result.ObjectiveValue, 1e-6)
+			assert.InDelta(t, 36, lp.ObjectiveValue(), 1e-6)
+			assert.InDeltaSlice(t, []float64{2e4, 6e-2}, result.Solution[:2], 1e-6)
+			assert.InDeltaSlice(t, []float64{0, 1.5e-6, 1e-2}, result.Duals, 1e-9)
+
+			// the tableau is unscaled, so the dual simplex algorithm can warm start
+			lp.AddConstraintGeq(1e4, 1, 0)
+			assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+			assert.InDelta(t, 33, lp.ObjectiveValue(), 1e-6)
+		}
+	}
+}
+
+func TestLP_scaleFactors(t *testing.T) {
+	lp := badlyScaledLP()
+	lp.SetScaling(EQUILIBRATION)
+	s := lp.scaleFactors()
+	// the slack variables have coefficients of 1
+	assert.Equal(t, []float64{1, math.Exp2(-28), math.Exp2(-14)}, s.rows)
+	for _, f := range s.columns {
+		// powers of 2 scale without rounding errors
+		assert.Equal(t, powerOfTwo(f), f)
+	}
+
+	lp.SetScaling(GEOMETRIC)
+	s = lp.scaleFactors()
+	scaled := lp.tableau.copy()
+	s.apply(scaled)
+	for _, row := range scaled[1:] {
+		for _, a := range row[1:] {
+			if a != 0 {
+				assert.InDelta(t, 0, math.Log2(math.Abs(a)), 4)
+			}
+		}
+	}
+
+	lp.SetEngine(EXACT)
+	assert.Nil(t, lp.scaleFactors())
+}
+
+func TestLP_SetScaling_Certificates(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1e-3, 1e-4, 1e2)
+	lp.AddConstraintLeq(2e6, 1e5, 1e3)
+	lp.SetScaling(GEOMETRIC)
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+	_, err := lp.Solution()
+	assertFarkas(t, lp, err)
+
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(1e3, 1e4, -1e-2)
+	lp.SetScaling(GEOMETRIC)
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+	_, err = lp.Solution()
+	if assert.IsType(t, UnboundedError{}, err) {
+		ray := err.(UnboundedError).Ray
+		// the ray keeps the constraint satisfied
+		assert.InDelta(t, 0, 1e4*ray[0]-1e-2*ray[1]+ray[2], 1e-9)
+	}
+
+	// bounded variables are scaled with their bounds
+	lp = badlyScaledLP()
+	lp.SetBounds(0, 1e3, 1e4)
+	lp.SetScaling(EQUILIBRATION)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDeltaSlice(t, []float64{1e4, 6e-2}, result.Solution[:2], 1e-6)
+}
+
+func TestLP_SetScaling_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for test := 0; test < 100; test++ {
+		n, m := 2+r.Intn(4), 1+r.Intn(4)
+		lp := NewLP()
+		objective := make([]float64, n)
+		for j := range objective {
+			objective[j] = float64(r.Intn(10))
+		}
+		lp.SetObjectiveFunction(MAXIMIZE, 0, objective...)
+		for i := 0; i < m; i++ {
+			scale := math.Pow(10, float64(r.Intn(9)-4))
+			coefs := make([]float64, n)
+			for j := range coefs {
+				coefs[j] = scale * float64(r.Intn(7)-1)
+			}
+			lp.AddConstraintGeq(scale*float64(1+r.Intn(20)), coefs...)
+		}
+		expected := lp.Optimize()
+		for _, scaling := range []int{GEOMETRIC, EQUILIBRATION} {
+			lp.SetScaling(scaling)
+			result := lp.Optimize()
+			assert.Equal(t, expected.Status, result.Status)
+			if result.Status == OPTIMAL {
+				assert.InDelta(t, expected.ObjectiveValue, result.ObjectiveValue, 1e-6)
+				assert.True(t, lp.satisfies(result.Solution))
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+)
+
+//Range is the interval [Lower, Upper]; an unbounded end is math.Inf
+type Range struct {
+	Lower float64
+	Upper float64
+}
+
+//SensitivityReport describes how the optimal solution of an LP responds to
+//changes in the LP. Constraints and variables are indexed as in the LP.
+type SensitivityReport struct {
+	//ShadowPrices is the dual value of each constraint, i.e. the change in
+	//objective value per unit increase of its b entry
+	ShadowPrices []float64
+	//ReducedCosts is c_j - yA_j for each variable; 0 for basic variables
+	ReducedCosts []float64
+	//RHSRanges is the range each b entry can take without changing the optimal basis
+	RHSRanges []Range
+	//ObjectiveRanges is the range each objective coefficient can take without
+	//changing the optimal basis
+	ObjectiveRanges []Range
+	//SlackColumns is the index of the slack variable of each constraint, or -1
+	//for equality constraints
+	SlackColumns []int
+}
+
+//Sensitivity returns the sensitivity report of the optimal LP derived from the
+//final tableau. LPs with bounds are not supported.
+func (lp *LP) Sensitivity() (*SensitivityReport, error) {
+	if _, err := lp.Solution(); err != nil {
+		return nil, err
+	}
+	if lp.bounded != nil {
+		return nil, InvalidInputError{s: "sensitivity analysis of LPs with bounds is not supported"}
+	}
+	lp.densify()
+	basis, inverse, err := lp.basisInverse()
+	if err != nil {
+		return nil, err
+	}
+	original := lp.original
+	m := lp.NumConstraints()
+	n := lp.Width() - 1
+	y := lp.duals(basis, inverse)
+	tol := lp.GetTolerances()
+
+	report := &SensitivityReport{
+		ShadowPrices:    make([]float64, m),
+		ReducedCosts:    make([]float64, n),
+		RHSRanges:       make([]Range, m),
+		ObjectiveRanges: make([]Range, n),
+		SlackColumns:    make([]int, m),
+	}
+
+	for i := 0; i < m; i++ {
+		info := lp.rowInfo(i)
+		report.ShadowPrices[i] = info.sign * y[i]
+		report.SlackColumns[i] = info.slack
+
+		// x_B + delta * B^-1 e_i >= 0
+		lo, hi := math.Inf(-1), math.Inf(1)
+		for k := range basis {
+			u := inverse[k][i]
+			xB := lp.tableau[k+1][0]
+			if Fgt(u, 0, tol.Pivot) {
+				lo = math.Max(lo, -xB/u)
+			} else if Flt(u, 0, tol.Pivot) {
+				hi = math.Min(hi, -xB/u)
+			}
+		}
+		b := original[i+1][0]
+		report.RHSRanges[i] = Range{b + lo, b + hi}
+		if info.sign < 0 {
+			report.RHSRanges[i] = Range{-(b + hi), -(b + lo)}
+		}
+	}
+
+	// the reduced costs of nonbasic variables are nonpositive when maximizing
+	// and nonnegative when minimizing, i.e. s*d <= 0 for the sense s
+	sense := lp.sense()
+	basicRow := make(map[int]int, m)
+	for r, c := range basis {
+		basicRow[c] = r
+	}
+	for j := 1; j <= n; j++ {
+		if _, ok := basicRow[j]; ok {
+			continue
+		}
+		d := original[0][j]
+		for i := range y {
+			d -= y[i] * original[i+1][j]
+		}
+		report.ReducedCosts[j-1] = d
+		report.ObjectiveRanges[j-1] = Range{math.Inf(-1), original[0][j] - d}
+		if sense < 0 {
+			report.ObjectiveRanges[j-1] = Range{original[0][j] - d, math.Inf(1)}
+		}
+	}
+
+	// the reduced costs d - delta*t of nonbasic variables must keep their sign
+	// when the objective coefficient of a basic variable changes by delta
+	for c, r := range basicRow {
+		lo, hi := math.Inf(-1), math.Inf(1)
+		for j := 1; j <= n; j++ {
+			if _, ok := basicRow[j]; ok {
+				continue
+			}
+			t := lp.tableau[r+1][j]
+			d := report.ReducedCosts[j-1]
+			st := sense * t
+			if Fgt(st, 0, tol.Pivot) {
+				lo = math.Max(lo, d/t)
+			} else if Flt(st, 0, tol.Pivot) {
+				hi = math.Min(hi, d/t)
+			}
+		}
+		report.ObjectiveRanges[c-1] = Range{original[0][c] + lo, original[0][c] + hi}
+	}
+
+	return report, nil
+}
+
+// the tableau columns of the basis and B^-1 from their original columns
+func (lp *LP) basisInverse() ([]int, [][]float64, error) {
+	basis, err := lp.basicColumns()
+	if err != nil {
+		return nil, nil, err
+	}
+	m := lp.NumConstraints()
+	B := make([][]float64, m)
+	for i := range B {
+		B[i] = make([]float64, m)
+		for k, c := range basis {
+			B[i][k] = lp.original[i+1][c]
+		}
+	}
+	inverse, err := invert(B, lp.GetTolerances().Pivot)
+	if err != nil {
+		return nil, nil, err
+	}
+	return basis, inverse, nil
+}
+
+// y = c_B B^-1 over the constraints of the tableau
+func (lp *LP) duals(basis []int, inverse [][]float64) []float64 {
+	y := make([]float64, len(basis))
+	for i := range y {
+		for k, c := range basis {
+			y[i] += lp.original[0][c] * inverse[k][i]
+		}
+	}
+	return y
+}
+
+// tableau column of the basic variable of each constraint
+func (lp *LP) basicColumns() ([]int, error) {
+	basis := lp.basis
+	if basis == nil {
+		basis = lp.findBasis()
+	}
+	columns := make([]int, len(basis))
+	for r, v := range basis {
+		if v < 0 {
+			return nil, SolutionUnavailableError{fmt.Sprintf("no basic variable in constraint %d", r)}
+		}
+		columns[r] = v + 1
+	}
+	return columns, nil
+}
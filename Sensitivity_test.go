@@ -0,0 +1,100 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// max 3x1 + 5x2 st. x1 <= 4, 2x2 <= 12, 3x1 + 2x2 <= 18
+func sensitivityLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 3, 5)
+	lp.AddConstraintGeq(4, 1, 0)
+	lp.AddConstraintGeq(12, 0, 2)
+	lp.AddConstraintGeq(18, 3, 2)
+	return lp
+}
+
+func assertRange(t *testing.T, expected, actual Range) {
+	if math.IsInf(expected.Lower, -1) {
+		assert.True(t, math.IsInf(actual.Lower, -1), "lower bound %v", actual.Lower)
+	} else {
+		assert.InDelta(t, expected.Lower, actual.Lower, EPSILON)
+	}
+	if math.IsInf(expected.Upper, 1) {
+		assert.True(t, math.IsInf(actual.Upper, 1), "upper bound %v", actual.Upper)
+	} else {
+		assert.InDelta(t, expected.Upper, actual.Upper, EPSILON)
+	}
+}
+
+func TestLP_Sensitivity(t *testing.T) {
+	lp := sensitivityLP()
+	_, err := lp.Sensitivity()
+	assert.Error(t, err)
+
+	lp.Optimize()
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0, 1.5, 1}, report.ShadowPrices, EPSILON)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, -1.5, -1}, report.ReducedCosts, EPSILON)
+	assert.Equal(t, []int{2, 3, 4}, report.SlackColumns)
+
+	inf := math.Inf(1)
+	for i, r := range []Range{{2, inf}, {6, 18}, {12, 24}} {
+		assertRange(t, r, report.RHSRanges[i])
+	}
+	for i, r := range []Range{{0, 7.5}, {2, inf}, {-4.5, 3}, {-inf, 1.5}, {-inf, 1}} {
+		assertRange(t, r, report.ObjectiveRanges[i])
+	}
+}
+
+func TestLP_SensitivityNegatedRow(t *testing.T) {
+	// 3x1 + 2x2 <= 18 written as -18 <= -3x1 - 2x2
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 3, 5)
+	lp.AddConstraintGeq(4, 1, 0)
+	lp.AddConstraintGeq(12, 0, 2)
+	lp.AddConstraintLeq(-18, -3, -2)
+	lp.Optimize()
+
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0, 1.5, -1}, report.ShadowPrices, EPSILON)
+	assertRange(t, Range{-24, -12}, report.RHSRanges[2])
+	assert.Equal(t, 4, lp.SlackColumn(2))
+}
+
+func TestLP_SensitivityEq(t *testing.T) {
+	lp := readLP("LP_feas_sef")
+	lp.Optimize()
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{2, .5, 0}, report.ShadowPrices, EPSILON)
+	assert.InDeltaSlice(t, []float64{0, 0, -1.5, -2, -.5, 0}, report.ReducedCosts, EPSILON)
+	assert.Equal(t, []int{-1, -1, -1}, report.SlackColumns)
+}
+
+func TestLP_SensitivityMinimize(t *testing.T) {
+	// min 2x1 + 3x2 st. x1 + x2 >= 4, x1 <= 3
+	lp := NewLP()
+	lp.SetObjectiveFunction(MINIMIZE, 0, 2, 3)
+	lp.AddConstraintLeq(4, 1, 1)
+	lp.AddConstraintGeq(3, 1, 0)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	assert.InDelta(t, 9, lp.ObjectiveValue(), EPSILON)
+
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{3, -1}, report.ShadowPrices, EPSILON)
+	assert.InDeltaSlice(t, []float64{0, 0, 3, 1}, report.ReducedCosts, EPSILON)
+
+	inf := math.Inf(1)
+	for i, r := range []Range{{3, inf}, {0, 4}} {
+		assertRange(t, r, report.RHSRanges[i])
+	}
+	for i, r := range []Range{{-inf, 3}, {2, inf}, {-3, inf}, {-1, inf}} {
+		assertRange(t, r, report.ObjectiveRanges[i])
+	}
+}
@@ -1,6 +1,7 @@
 package sago
 
 import (
+	"context"
 	"math"
 )
 
@@ -13,7 +14,18 @@ See sample usage in Simplex_test > Test_USE_EXAMPLE
 //Feasible returns true if the LP is feasible, false otherwise
 func (lp *LP) Feasible() bool {
 	if !lp.feasibilityKnown {
-		lp.simplexPhaseI()
+		if lp.warm || lp.sparse != nil && lp.sparseSolvable() {
+			lp.solve()
+		} else {
+			lp.densify()
+			lp.snapshot()
+			if lp.applyBounds() {
+				lp.simplexPhaseI()
+			} else {
+				lp.feasibilityKnown = true
+				lp.feasible = false
+			}
+		}
 	}
 	return lp.feasible
 }
@@ -23,8 +35,9 @@ func (lp *LP) Optimal() bool {
 	if lp.optimal {
 		return lp.optimal
 	}
+	tol := lp.GetTolerances()
 	for _, i := range lp.tableau[0][1:] {
-		if Flt(i, 0, EPSILON) {
+		if Flt(i, 0, tol.DualFeasibility) {
 			return false
 		}
 	}
@@ -32,14 +45,63 @@ func (lp *LP) Optimal() bool {
 	return true
 }
 
-//Optimize executes the simplex algorithm on the LP
-func (lp *LP) Optimize() {
-	if lp.objective == MAXIMIZE {
-		lp.negateObjectiveFunction()
+//Optimize executes the simplex algorithm on the LP and returns its status and
+//solution. If the LP was optimal and only inequality constraints have been added
+//since, Optimize starts from the previous optimal basis and restores
+//feasibility with the dual simplex algorithm.
+func (lp *LP) Optimize() *Result {
+	return lp.OptimizeContext(context.Background(), SolveOptions{})
+}
+
+// executes the simplex algorithm, warm started if possible
+func (lp *LP) solve() {
+	if !lp.sparseSolvable() {
+		lp.densify()
+	}
+	if !lp.warmStart() {
+		lp.reset()
+		lp.snapshot()
+		if !lp.presolve || lp.engine == EXACT || !lp.optimizePresolved() {
+			lp.optimize()
+		}
+	}
+	lp.warm = lp.optimal
+}
+
+func (lp *LP) optimize() {
+	if lp.engine == EXACT && lp.trace == nil && lp.hasBounds() && lp.exactBoundedSimplex() {
+		return
+	}
+	if !lp.applyBounds() {
+		lp.feasibilityKnown = true
+		lp.feasible = false
+		lp.unbounded = false
+		lp.optimal = false
+		lp.solved = true
+		return
+	}
+	s := lp.scaleFactors()
+	lp.scale(s)
+	defer lp.unscale(s)
+	if lp.engine == EXACT && lp.bounded == nil && lp.trace == nil {
+		lp.exactSimplex()
+		return
 	}
+	if (lp.engine == REVISED || lp.engine == SPARSE) && lp.bounded == nil && lp.trace == nil {
+		if lp.revisedSimplex() == nil {
+			return
+		}
+		// the basis became numerically singular; start over with the tableau engine
+		lp.densify()
+		lp.tableau = lp.original.copy()
+		lp.scale(s)
+	}
+	// phase I leaves the objective function alone, except for complementing
+	// bounded variables, which expects it negated
+	lp.negateObjectiveFunction()
 	lp.simplexPhaseI()
-	if lp.objective == MINIMIZE {
-		lp.negateObjectiveFunction()
+	if lp.control.stopped() {
+		return
 	}
 	if !lp.feasible {
 		lp.unbounded = false
@@ -47,21 +109,99 @@ func (lp *LP) Optimize() {
 		lp.solved = true
 		return
 	}
+	lp.priceOut()
 	lp.simplex()
 }
 
+//Basis returns the index of the basic variable of each constraint, or -1 if
+//the constraint has no basic variable (e.g. it is redundant). The basis is
+//known once the LP has been optimized.
+func (lp *LP) Basis() []int {
+	return append([]int{}, lp.basis...)
+}
+
 func (lp *LP) simplexPhaseI() {
 	A := lp.auxLP()
+	A.pivotRule = lp.pivotRule
+	A.control = lp.control
+	A.trace = lp.trace
+	A.tolerances = lp.tolerances
+	lp.setPhase(PHASEI)
+	if lp.bounded != nil {
+		A.bounded = lp.bounded.copy()
+		A.bounded.extend(A.Width() - 1)
+	}
 	A.auxPreSimplex()
 	A.simplex()
+	if lp.control.stopped() {
+		return
+	}
+	lp.setPhase(PHASEII)
 	if !lp.feasibilityKnown {
 		lp.feasibilityKnown = true
-		lp.feasible = Feq(A.ObjectiveValue(), 0, EPSILON)
+		lp.feasible = Feq(A.ObjectiveValue(), 0, lp.GetTolerances().PrimalFeasibility)
+		if !lp.feasible && lp.bounded == nil {
+			lp.farkas = lp.farkasVector(A)
+		}
 	}
 	l := len(lp.tableau[0])
+	A.driveOutArtificials(l - 1)
 	for i := range lp.tableau[1:] {
 		lp.tableau[i+1] = A.tableau[i+1][:l]
 	}
+	lp.basis = A.basis
+	for r, v := range lp.basis {
+		if v >= l-1 {
+			lp.basis[r] = -1
+		}
+	}
+	if lp.bounded != nil {
+		// complement the variables that phase I complemented in the objective function
+		for j, d := range lp.bounded.direction {
+			if A.bounded.direction[j] != d {
+				u := lp.bounded.upper[j]
+				if lp.bounded.free[j] {
+					u = 0
+				}
+				complementColumn(lp.tableau[0], j, u)
+			}
+		}
+		lp.bounded.offset = A.bounded.offset[:l-1]
+		lp.bounded.direction = A.bounded.direction[:l-1]
+	}
+}
+
+// pivots basic artificial variables (index >= variables) out of the basis of
+// the auxiliary LP where the constraint has a nonzero original variable
+func (lp *LP) driveOutArtificials(variables int) {
+	tol := lp.GetTolerances()
+	for r, v := range lp.basis {
+		if v < variables {
+			continue
+		}
+		for c := 1; c <= variables; c++ {
+			if !Feq(lp.tableau[r+1][c], 0, tol.Pivot) {
+				lp.pivot(r+1, c)
+				break
+			}
+		}
+	}
+}
+
+// makes the objective function coefficients of basic variables 0
+func (lp *LP) priceOut() {
+	for r, v := range lp.basis {
+		if v < 0 {
+			continue
+		}
+		factor := lp.tableau[0][v+1]
+		if factor == 0 {
+			continue
+		}
+		for j := range lp.tableau[0] {
+			lp.tableau[0][j] -= factor * lp.tableau[r+1][j]
+		}
+	}
 }
 
 func (lp *LP) auxPreSimplex() {
@@ -74,7 +214,13 @@ func (lp *LP) auxPreSimplex() {
 }
 
 func (lp *LP) simplex() {
-	for !lp.solved {
+	if r, ok := lp.pivotRule.(restartingRule); ok {
+		if lp.basis == nil {
+			lp.basis = lp.findBasis()
+		}
+		r.restart(lp.basis)
+	}
+	for !lp.solved && !lp.control.stopped() {
 		lp.simplexIteration()
 	}
 }
@@ -83,6 +229,12 @@ func (lp *LP) simplexIteration() {
 	if lp.solved {
 		return
 	}
+	if lp.basis == nil {
+		lp.basis = lp.findBasis()
+	}
+	if lp.bounded != nil {
+		lp.complementFreeColumns(0)
+	}
 
 	// Test optimal
 	if lp.Optimal() {
@@ -93,24 +245,59 @@ func (lp *LP) simplexIteration() {
 
 	// Choose column to perform ratio test
 	col := 1
-	for i, j := range lp.tableau[0][1:] {
-		if Flt(j, 0, EPSILON) {
-			col = i + 1
-			break
+	if lp.pivotRule != nil {
+		col = lp.pivotRule.Entering(lp.tableau, lp.basis, lp.GetTolerances())
+		if col == 0 {
+			lp.solved = true
+			lp.unbounded = false
+			return
 		}
+	} else {
+		tol := lp.GetTolerances()
+		for i, j := range lp.tableau[0][1:] {
+			if Flt(j, 0, tol.DualFeasibility) {
+				col = i + 1
+				break
+			}
+		}
+	}
+
+	if !lp.control.next() {
+		return
 	}
+	lp.trace.begin(lp.tableau)
 
 	// Ratio test
-	row := lp.ratioTest(col)
+	if lp.bounded != nil {
+		if leaving := lp.boundedPivot(col); !lp.unbounded {
+			lp.reportIteration(col-1, leaving)
+		}
+		return
+	}
+	var row int
+	if lp.pivotRule != nil {
+		row = lp.pivotRule.Leaving(lp.tableau, lp.basis, col, lp.GetTolerances())
+	} else {
+		row = lp.ratioTest(col)
+	}
 
 	// unbounded
 	if row == 0 {
+		lp.setRay(col)
 		lp.unbounded = true
 		lp.solved = true
 		lp.optimal = false
 		return
 	}
 
+	leaving := lp.basis[row-1]
+	lp.pivot(row, col)
+	lp.trace.record(lp.tableau, row, col)
+	lp.reportIteration(col-1, leaving)
+}
+
+// pivots on tableau[row][col], making column col basic in the constraint row
+func (lp *LP) pivot(row, col int) {
 	// Set the selected variable to 1 by multiplying row by the inverse of variable
 	inverse := 1 / (lp.tableau)[row][col]
 	for i := range (lp.tableau)[row] {
@@ -118,15 +305,43 @@ func (lp *LP) simplexIteration() {
 	}
 
 	// make all other values in column 0
+	tol := lp.GetTolerances()
 	for i, r := range lp.tableau {
 		factor := r[col]
-		if Feq(factor, 0, EPSILON) || i == row {
+		if Feq(factor, 0, tol.Zero) || i == row {
 			continue
 		}
 		for j := range r {
 			(lp.tableau)[i][j] -= factor * (lp.tableau)[row][j]
 		}
 	}
+	if lp.basis != nil {
+		lp.basis[row-1] = col - 1
+	}
+}
+
+// finds the basic variable of each constraint by looking for unit columns; used
+// when the tableau was built directly instead of through Optimize
+func (lp *LP) findBasis() []int {
+	tol := lp.GetTolerances()
+	basis := make([]int, lp.NumConstraints())
+	for r := range basis {
+		basis[r] = -1
+	Search:
+		for c := 1; c < lp.Width(); c++ {
+			if !Feq(lp.tableau[r+1][c], 1, tol.Zero) {
+				continue
+			}
+			for i, row := range lp.ListConstraints() {
+				if i != r && !Feq(row[c], 0, tol.Zero) {
+					continue Search
+				}
+			}
+			basis[r] = c - 1
+			break
+		}
+	}
+	return basis
 }
 
 // returns the index of the constraint with the lowest ratio
@@ -134,12 +349,13 @@ func (lp *LP) simplexIteration() {
 func (lp *LP) ratioTest(column int) int {
 	row := -1
 	low := math.MaxFloat64
+	tol := lp.GetTolerances()
 	for i, c := range lp.ListConstraints() {
-		if Fle(c[column], 0, EPSILON) { // divide by 0
+		if Fle(c[column], 0, tol.Pivot) { // divide by 0
 			continue
 		}
 		ratio := c[0] / c[column]
-		if Fle(0, ratio, EPSILON) && Flt(ratio, low, EPSILON) {
+		if Fle(0, ratio, tol.Zero) && Flt(ratio, low, tol.Zero) {
 			row, low = i, ratio
 		}
 	}
@@ -146,9 +146,9 @@ func TestLP_Optimize3(t *testing.T) {
 	assert.True(t, lp.optimal)
 	assert.False(t, lp.unbounded)
 	assert.True(t, lp.Feasible())
-	assert.Equal(t, float64(10), lp.ObjectiveValue())
+	assert.Equal(t, float64(0), lp.ObjectiveValue())
 	solution, _ := lp.Solution()
-	assert.Equal(t, []float64{2, 2, 0, 0}, solution)
+	assert.Equal(t, []float64{0, 0, 6, 4}, solution)
 }
 
 func TestLP_Optimize4(t *testing.T) {
@@ -162,11 +162,9 @@ func TestLP_Optimize4(t *testing.T) {
 	assert.True(t, lp.optimal)
 	assert.False(t, lp.unbounded)
 	assert.True(t, lp.Feasible())
-	assert.Equal(t, float64(5), lp.ObjectiveValue())
+	assert.Equal(t, float64(0), lp.ObjectiveValue())
 	solution, _ := lp.Solution()
-	x1 := float64(2) / 3
-	x2 := float64(1) / 3
-	assert.Equal(t, []float64{x1, x2, 0, 0}, solution)
+	assert.Equal(t, []float64{0, 0, 1, 1}, solution)
 }
 
 func TestLP_Optimize5(t *testing.T) {
@@ -218,6 +216,47 @@ func TestLP_Optimize7(t *testing.T) {
 	assert.Equal(t, []float64{0, 0, 0, 2}, sol)
 }
 
+func TestLP_Basis(t *testing.T) {
+	lp := readLP("LP_feas_sef")
+	assert.Empty(t, lp.Basis())
+	lp.Optimize()
+	assert.Equal(t, []int{1, 0, 5}, lp.Basis())
+
+	// x2 is nonbasic but has a 1 in its column and a 0 in the objective function
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintGeq(4, 1, 1)
+	lp.Optimize()
+	assert.Equal(t, []int{0}, lp.Basis())
+	sol, _ := lp.Solution()
+	assert.Equal(t, []float64{4, 0, 0}, sol)
+}
+
+func TestLP_OptimizePriceOut(t *testing.T) {
+	// x1 is basic after phase I and has to be priced out of the objective function
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, -1, 0)
+	lp.AddConstraintEq(5, 1, 1)
+	lp.AddConstraintEq(1, 1, -1)
+	lp.Optimize()
+	assert.True(t, lp.Optimal())
+	assert.Equal(t, float64(-3), lp.ObjectiveValue())
+	sol, _ := lp.Solution()
+	assert.Equal(t, []float64{3, 2}, sol)
+}
+
+func TestLP_OptimizeRedundant(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintEq(2, 1, 1)
+	lp.AddConstraintEq(4, 2, 2)
+	lp.Optimize()
+	assert.Contains(t, lp.Basis(), -1)
+	assert.Equal(t, float64(2), lp.ObjectiveValue())
+	sol, _ := lp.Solution()
+	assert.Equal(t, float64(2), sol[0]+sol[1])
+}
+
 /*
 Example of how to optimize using simplex to solve:
 Max 	    3 x_1 + 5 x_2
@@ -0,0 +1,270 @@
+package sago
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// nonzero entries value[k] at index[k] of a vector, by increasing index
+type sparseVector struct {
+	index []int
+	value []float64
+}
+
+// the nonzero entries of a dense vector
+func newSparseVector(dense []float64) sparseVector {
+	var v sparseVector
+	for i, a := range dense {
+		if a != 0 {
+			v.index = append(v.index, i)
+			v.value = append(v.value, a)
+		}
+	}
+	return v
+}
+
+func (v sparseVector) dot(x []float64) float64 {
+	sum := 0.0
+	for k, i := range v.index {
+		sum += v.value[k] * x[i]
+	}
+	return sum
+}
+
+func (v sparseVector) dense(n int) []float64 {
+	x := make([]float64, n)
+	for k, i := range v.index {
+		x[i] = v.value[k]
+	}
+	return x
+}
+
+// matrix in compressed sparse row (CSR) form, or compressed sparse column (CSC)
+// form when its lines are columns: the entries of line k are
+// index[start[k]:start[k+1]] and value[start[k]:start[k+1]]
+type sparseMatrix struct {
+	start []int
+	index []int
+	value []float64
+}
+
+func (s *sparseMatrix) len() int {
+	if s == nil || len(s.start) == 0 {
+		return 0
+	}
+	return len(s.start) - 1
+}
+
+func (s *sparseMatrix) line(k int) sparseVector {
+	lo, hi := s.start[k], s.start[k+1]
+	return sparseVector{index: s.index[lo:hi], value: s.value[lo:hi]}
+}
+
+func (s *sparseMatrix) appendLine(v sparseVector) {
+	if len(s.start) == 0 {
+		s.start = []int{0}
+	}
+	s.index = append(s.index, v.index...)
+	s.value = append(s.value, v.value...)
+	s.start = append(s.start, len(s.index))
+}
+
+// the transpose with n lines, i.e. the CSC form of a CSR matrix of n columns
+// and the other way around
+func (s *sparseMatrix) transpose(n int) *sparseMatrix {
+	t := &sparseMatrix{
+		start: make([]int, n+1),
+		index: make([]int, len(s.index)),
+		value: make([]float64, len(s.value)),
+	}
+	for _, j := range s.index {
+		t.start[j+1]++
+	}
+	for j := 0; j < n; j++ {
+		t.start[j+1] += t.start[j]
+	}
+	next := append([]int{}, t.start[:n]...)
+	for k := 0; k < s.len(); k++ {
+		line := s.line(k)
+		for e, j := range line.index {
+			t.index[next[j]] = k
+			t.value[next[j]] = line.value[e]
+			next[j]++
+		}
+	}
+	return t
+}
+
+func (s *sparseMatrix) copy() *sparseMatrix {
+	return &sparseMatrix{
+		start: append([]int(nil), s.start...),
+		index: append([]int(nil), s.index...),
+		value: append([]float64(nil), s.value...),
+	}
+}
+
+// constraints b_i = A_i x added with AddConstraintSparse, with A in CSR form
+type sparseConstraints struct {
+	b    []float64
+	rows sparseMatrix
+}
+
+// the i-th constraint as a row (b, a1, a2, ...) of the tableau of the given width
+func (s *sparseConstraints) row(i, width int) []float64 {
+	row := make([]float64, width)
+	row[0] = s.b[i]
+	line := s.rows.line(i)
+	for k, j := range line.index {
+		row[j+1] = line.value[k]
+	}
+	return row
+}
+
+func (s *sparseConstraints) len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.b)
+}
+
+func (s *sparseConstraints) copy() *sparseConstraints {
+	if s == nil {
+		return nil
+	}
+	return &sparseConstraints{b: append([]float64(nil), s.b...), rows: *s.rows.copy()}
+}
+
+//AddConstraintSparse adds the equality constraint b = a1x1 + a2x2 + ... where
+//the coefficients are given by their nonzero entries: vals[k] is the
+//coefficient of the variable at index idx[k]. Entries with the same index are
+//added up. The constraints of an LP built with the sparse builders alone are
+//stored by row (CSR) and only the REVISED and SPARSE engines solve them without
+//expanding them to rows of the tableau; other engines, bounds, scaling, presolve, tracing
+//and everything that reads the tableau, such as ListConstraints and
+//Sensitivity, expand them first. Constraints added to an LP that already has
+//dense constraints are stored densely.
+func (lp *LP) AddConstraintSparse(b float64, idx []int, vals []float64) error {
+	return lp.addSparseConstraint(rowInfo{kind: constraintEq, slack: -1}, b, idx, vals)
+}
+
+//AddConstraintSparseGeq is like AddConstraintSparse but for >= constraints, as
+//added by AddConstraintGeq
+func (lp *LP) AddConstraintSparseGeq(b float64, idx []int, vals []float64) error {
+	return lp.addSparseConstraint(rowInfo{kind: constraintGeq}, b, idx, vals)
+}
+
+//AddConstraintSparseLeq is like AddConstraintSparse but for <= constraints, as
+//added by AddConstraintLeq
+func (lp *LP) AddConstraintSparseLeq(b float64, idx []int, vals []float64) error {
+	return lp.addSparseConstraint(rowInfo{kind: constraintLeq}, b, idx, vals)
+}
+
+func (lp *LP) addSparseConstraint(info rowInfo, b float64, idx []int, vals []float64) error {
+	if len(idx) != len(vals) {
+		return InvalidInputError{s: fmt.Sprintf("%d indices for %d values", len(idx), len(vals))}
+	}
+	for k, j := range idx {
+		if j < 0 {
+			return InvalidInputError{s: fmt.Sprintf("negative index %d", j)}
+		}
+		if math.IsNaN(vals[k]) || math.IsInf(vals[k], 0) {
+			return InvalidInputError{s: fmt.Sprintf("coefficient %v of variable %d", vals[k], j)}
+		}
+	}
+	v := sortedSparseVector(idx, vals)
+
+	// the slack variable takes the next column, as in AddConstraintGeq
+	if info.kind != constraintEq {
+		info.slack = lp.width - 1
+		if n := len(v.index); n > 0 && v.index[n-1] >= info.slack {
+			info.slack = v.index[n-1] + 1
+		}
+		v.index = append(v.index, info.slack)
+		v.value = append(v.value, 1)
+		if info.kind == constraintLeq {
+			v.value[len(v.value)-1] = -1
+		}
+	}
+
+	if lp.sparse == nil && lp.NumConstraints() > 0 {
+		coefficients := []float64{}
+		if n := len(v.index); n > 0 {
+			coefficients = v.dense(v.index[n-1] + 1)
+		}
+		lp.addConstraint(info, b, coefficients)
+		return nil
+	}
+
+	lp.reset()
+	info.sign = 1
+	if Flt(b, 0, lp.GetTolerances().Zero) {
+		b = -b
+		ScalarVectorMultiply(-1, v.value)
+		info.sign = -1
+	}
+	if n := len(v.index); n > 0 && v.index[n-1]+2 > lp.width {
+		lp.increaseWidth(v.index[n-1] + 2)
+	}
+	if lp.sparse == nil {
+		lp.sparse = &sparseConstraints{}
+	}
+	lp.sparse.b = append(lp.sparse.b, b)
+	lp.sparse.rows.appendLine(v)
+	lp.rows = append(lp.rows, info)
+	lp.basis = nil
+	return nil
+}
+
+// the entries sorted by index, with duplicates added up and zeros dropped
+func sortedSparseVector(idx []int, vals []float64) sparseVector {
+	order := make([]int, len(idx))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool { return idx[order[a]] < idx[order[b]] })
+	var v sparseVector
+	for _, k := range order {
+		if n := len(v.index); n > 0 && v.index[n-1] == idx[k] {
+			v.value[n-1] += vals[k]
+			continue
+		}
+		v.index = append(v.index, idx[k])
+		v.value = append(v.value, vals[k])
+	}
+	nonzero := sparseVector{}
+	for k, j := range v.index {
+		if v.value[k] != 0 {
+			nonzero.index = append(nonzero.index, j)
+			nonzero.value = append(nonzero.value, v.value[k])
+		}
+	}
+	return nonzero
+}
+
+// true if the REVISED or SPARSE engine can solve the LP without expanding its
+// sparse constraints to rows of the tableau
+func (lp *LP) sparseSolvable() bool {
+	return (lp.engine == REVISED || lp.engine == SPARSE) && lp.trace == nil && lp.scaling == NOSCALING && !lp.presolve && !lp.hasBounds()
+}
+
+// turns the constraints added with AddConstraintSparse into rows of the
+// tableau, and writes the rows of the final tableau of the REVISED or SPARSE
+// engine if it has not been written yet
+func (lp *LP) densify() {
+	if s := lp.sparse; s != nil {
+		lp.sparse = nil
+		width := lp.Width()
+		for i := range s.b {
+			row := s.row(i, width)
+			lp.tableau = append(lp.tableau, row)
+			if lp.dirty {
+				lp.original = append(lp.original, append([]float64{}, row...))
+			}
+		}
+	}
+	if rs := lp.revised; rs != nil {
+		lp.revised = nil
+		rs.writeRows()
+	}
+}
@@ -0,0 +1,225 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// sensitivityLP built with the sparse builders
+func sparseSensitivityLP() *LP {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 3, 5)
+	lp.AddConstraintSparseGeq(4, []int{0}, []float64{1})
+	lp.AddConstraintSparseGeq(12, []int{1}, []float64{2})
+	lp.AddConstraintSparseGeq(18, []int{1, 0}, []float64{2, 3})
+	return lp
+}
+
+// max c x st. a mix of Ax <= b, Ax >= b and Ax = b with a few nonzeros per
+// constraint, built densely and sparsely
+func randomSparseLP(r *rand.Rand, m, n, nonzeros int) (*LP, *LP) {
+	dense, sparse := NewLP(), NewLP()
+	c := make([]float64, n)
+	for j := range c {
+		c[j] = float64(r.Intn(20) - 5)
+	}
+	dense.SetObjectiveFunction(MAXIMIZE, 0, c...)
+	sparse.SetObjectiveFunction(MAXIMIZE, 0, c...)
+	for i := 0; i < m; i++ {
+		idx := make([]int, nonzeros)
+		vals := make([]float64, nonzeros)
+		a := make([]float64, n)
+		for k := range idx {
+			idx[k] = r.Intn(n)
+			vals[k] = float64(r.Intn(10) + 1)
+			a[idx[k]] += vals[k]
+		}
+		b := float64(r.Intn(100) + 50)
+		switch r.Intn(6) {
+		case 0:
+			dense.AddConstraintLeq(b/10, a...)
+			sparse.AddConstraintSparseLeq(b/10, idx, vals)
+		case 1:
+			dense.AddConstraintEq(b, a...)
+			sparse.AddConstraintSparse(b, idx, vals)
+		default:
+			dense.AddConstraintGeq(b, a...)
+			sparse.AddConstraintSparseGeq(b, idx, vals)
+		}
+	}
+	// keeps the LP bounded
+	idx := make([]int, n)
+	a := make([]float64, n)
+	for j := range idx {
+		idx[j], a[j] = j, 1
+	}
+	dense.AddConstraintGeq(1000, a...)
+	sparse.AddConstraintSparseGeq(1000, idx, a)
+	return dense, sparse
+}
+
+func TestLP_AddConstraintSparse(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1, 1)
+	assert.NoError(t, lp.AddConstraintSparse(-4, []int{2, 0, 2}, []float64{1, 2, 3}))
+	assert.NoError(t, lp.AddConstraintSparseGeq(5, []int{4}, []float64{1}))
+	assert.NoError(t, lp.AddConstraintSparseLeq(1, []int{1, 1}, []float64{1, -1}))
+	assert.Equal(t, 3, lp.NumConstraints())
+	assert.Equal(t, 8, lp.Width())
+	assert.Equal(t, 1, len(lp.tableau))
+	assert.Equal(t, []int{-1, 5, 6}, []int{lp.SlackColumn(0), lp.SlackColumn(1), lp.SlackColumn(2)})
+
+	expected := NewLP()
+	expected.SetObjectiveFunction(MAXIMIZE, 0, 1, 1, 1)
+	expected.AddConstraintEq(-4, 2, 0, 4)
+	expected.AddConstraintGeq(5, 0, 0, 0, 0, 1)
+	expected.AddConstraintLeq(1)
+	assert.Equal(t, expected.ListConstraints(), lp.ListConstraints())
+	assert.Nil(t, lp.sparse)
+	assert.Equal(t, expected.rows, lp.rows)
+
+	// stored densely once the LP has dense constraints
+	assert.NoError(t, lp.AddConstraintSparseGeq(2, []int{0}, []float64{1}))
+	expected.AddConstraintGeq(2, 1)
+	assert.Nil(t, lp.sparse)
+	assert.Equal(t, expected.ListConstraints(), lp.ListConstraints())
+
+	assert.IsType(t, InvalidInputError{}, lp.AddConstraintSparse(1, []int{0, 1}, []float64{1}))
+	assert.IsType(t, InvalidInputError{}, lp.AddConstraintSparse(1, []int{-1}, []float64{1}))
+	assert.Equal(t, 4, lp.NumConstraints())
+}
+
+func TestLP_OptimizeSparse(t *testing.T) {
+	lp := sparseSensitivityLP()
+	lp.SetEngine(SPARSE)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.InDelta(t, 36, result.ObjectiveValue, EPSILON)
+	assert.InDeltaSlice(t, []float64{2, 6, 2, 0, 0}, result.Solution, EPSILON)
+	assert.InDeltaSlice(t, []float64{0, 1.5, 1}, result.Duals, EPSILON)
+	assert.Equal(t, 1, len(lp.tableau))
+
+	// the tableau is written when it is needed
+	report, err := lp.Sensitivity()
+	assert.NoError(t, err)
+	expected := sensitivityLP()
+	expected.Optimize()
+	expectedReport, _ := expected.Sensitivity()
+	assert.Equal(t, expectedReport.ReducedCosts, report.ReducedCosts)
+	assert.Equal(t, expectedReport.RHSRanges, report.RHSRanges)
+	assert.Equal(t, expectedReport.ObjectiveRanges, report.ObjectiveRanges)
+	assert.Equal(t, 4, len(lp.tableau))
+	assert.ElementsMatch(t, expected.Basis(), lp.Basis())
+
+	// warm start from the sparse solution
+	lp = sparseSensitivityLP()
+	lp.SetEngine(SPARSE)
+	lp.Optimize()
+	c := lp.Copy()
+	lp.AddConstraintGeq(1, 1)
+	assert.True(t, lp.warm)
+	assert.Equal(t, OPTIMAL, lp.Optimize().Status)
+	assert.InDelta(t, 33, lp.ObjectiveValue(), EPSILON)
+	sol, err := c.Solution()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{2, 6, 2, 0, 0}, sol, EPSILON)
+
+	lp = NewLP()
+	lp.SetEngine(SPARSE)
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 1)
+	lp.AddConstraintSparseGeq(1, []int{0}, []float64{1})
+	assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+	_, err = lp.Solution()
+	assert.Equal(t, []float64{0, 1, 0}, err.(UnboundedError).Ray)
+
+	lp.AddConstraintSparseLeq(2, []int{0}, []float64{1})
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+	assert.False(t, lp.Feasible())
+	assert.NotNil(t, lp.sparse)
+}
+
+func TestLP_OptimizeSparseFixtures(t *testing.T) {
+	for _, name := range []string{"LP_feas_sef", "LP_feas_min", "LP_sol_0", "LP_degenerate_iteration", "LP_infeasibility_rounding_error", "LP_unbounded_trivial", "LP_infeas"} {
+		expected := readLP(name)
+		expected.Optimize()
+		lp := readLP(name)
+		lp.SetEngine(SPARSE)
+		lp.Optimize()
+		assert.Equal(t, expected.Feasible(), lp.Feasible(), name)
+		assert.Equal(t, expected.Bounded(), lp.Bounded(), name)
+		if expected.Optimal() {
+			assert.InDelta(t, expected.ObjectiveValue(), lp.ObjectiveValue(), 1e-6, name)
+		}
+	}
+}
+
+func TestLP_OptimizeSparseRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 50; k++ {
+		expected, lp := randomSparseLP(r, 15, 30, 4)
+		// the REVISED engine solves sparse constraints without the tableau too
+		lp.SetEngine([]int{SPARSE, REVISED}[k%2])
+		expectedResult := expected.Optimize()
+		result := lp.Optimize()
+		assert.Equal(t, expectedResult.Status, result.Status, k)
+		if result.Status == OPTIMAL {
+			assert.InDelta(t, expectedResult.ObjectiveValue, result.ObjectiveValue, 1e-6, k)
+			assert.Equal(t, len(expectedResult.Duals), len(result.Duals), k)
+			assert.Equal(t, 1, len(lp.tableau), k)
+		}
+	}
+}
+
+func TestLP_OptimizeSparseLarge(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	const m, n = 300, 10000
+	columns := make([][]float64, n)
+	for j := range columns {
+		columns[j] = make([]float64, m)
+	}
+	b := make([]float64, m)
+	idx := make([][]int, m)
+	vals := make([][]float64, m)
+	for i := range b {
+		b[i] = float64(r.Intn(100) + 50)
+		idx[i] = make([]int, 5)
+		vals[i] = make([]float64, 5)
+		for k := range idx[i] {
+			idx[i][k] = r.Intn(n)
+			vals[i][k] = float64(r.Intn(10) + 1)
+			columns[idx[i][k]][i] += vals[i][k]
+		}
+	}
+	// the variables that are in no constraint do not count
+	c := make([]float64, n)
+	for j := range c {
+		if newSparseVector(columns[j]).index != nil {
+			c[j] = float64(r.Intn(20) + 1)
+		}
+	}
+	lp := NewLP()
+	lp.SetEngine(SPARSE)
+	lp.SetObjectiveFunction(MAXIMIZE, 0, c...)
+	for i := range b {
+		assert.NoError(t, lp.AddConstraintSparseGeq(b[i], idx[i], vals[i]))
+	}
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	assert.Equal(t, 1, len(lp.tableau))
+
+	// the duals prove optimality: y >= 0, yA >= c and yb = cx
+	yb := 0.0
+	for i, y := range result.Duals {
+		assert.True(t, Fge(y, 0, 1e-9))
+		yb += y * b[i]
+	}
+	for j := range columns {
+		yA := 0.0
+		for i, a := range columns[j] {
+			yA += result.Duals[i] * a
+		}
+		assert.True(t, Fge(yA, c[j], 1e-6), j)
+	}
+	assert.InDelta(t, result.ObjectiveValue, yb, 1e-6)
+}
@@ -0,0 +1,27 @@
+NAME          EXAMPLE
+* max 3x + 5y + z + 2 with y integer and z binary
+OBJSENSE
+    MAX
+ROWS
+ N  PROFIT
+ L  LIM1
+ L  LIM2
+ L  LIM3
+COLUMNS
+    X         PROFIT         3.0   LIM1           1.0
+    X         LIM3           3.0
+    MARKER                 'MARKER'                 'INTORG'
+    Y         PROFIT         5.0   LIM2           2.0
+    Y         LIM3           2.0
+    MARKER                 'MARKER'                 'INTEND'
+    Z         PROFIT         1.0
+RHS
+    RHS       PROFIT        -2.0
+    RHS       LIM1           4.0   LIM2          12.0
+    RHS       LIM3          18.0
+RANGES
+    RNG       LIM3           6.0
+BOUNDS
+ UP BND       X             10.0
+ BV BND       Z
+ENDATA
@@ -0,0 +1,14 @@
+NAME          MINEXAMPLE
+* min 2x + 3y + 1 st. x + y >= 4, x <= 3, without OBJSENSE
+ROWS
+ N  COST
+ G  DEMAND
+ L  CAP
+COLUMNS
+    X         COST           2.0   DEMAND         1.0
+    X         CAP            1.0
+    Y         COST           3.0   DEMAND         1.0
+RHS
+    RHS       COST          -1.0
+    RHS       DEMAND         4.0   CAP            3.0
+ENDATA
@@ -0,0 +1,58 @@
+package sago
+
+//Tolerances are the numerical tolerances of the simplex algorithm. A field of 0
+//takes the default, EPSILON. Pivot rules set with SetPivotRule are passed the
+//tolerances of the LP.
+type Tolerances struct {
+	//PrimalFeasibility is the violation of a constraint or bound that still
+	//counts as satisfied, in phase I and the dual simplex algorithm
+	PrimalFeasibility float64
+	//DualFeasibility is the reduced cost that still counts as not improving the
+	//objective function, in the optimality test
+	DualFeasibility float64
+	//Pivot is the smallest absolute value of a pivot element in the ratio test
+	Pivot float64
+	//Zero is the absolute value that counts as 0 when comparing ratios and
+	//eliminating a column; it should not exceed the other tolerances, or values
+	//they tell apart from 0 are not eliminated
+	Zero float64
+}
+
+//DefaultTolerances returns the tolerances of a new LP
+func DefaultTolerances() Tolerances {
+	return Tolerances{
+		PrimalFeasibility: EPSILON,
+		DualFeasibility:   EPSILON,
+		Pivot:             EPSILON,
+		Zero:              EPSILON,
+	}
+}
+
+//GetTolerances returns the numerical tolerances of the simplex algorithm
+func (lp *LP) GetTolerances() Tolerances {
+	return lp.tolerances.withDefaults()
+}
+
+//SetTolerances sets the numerical tolerances of the simplex algorithm; the next
+//Optimize starts over
+func (lp *LP) SetTolerances(tolerances Tolerances) {
+	lp.reset()
+	lp.tolerances = tolerances
+}
+
+func (t Tolerances) withDefaults() Tolerances {
+	defaults := DefaultTolerances()
+	if t.PrimalFeasibility == 0 {
+		t.PrimalFeasibility = defaults.PrimalFeasibility
+	}
+	if t.DualFeasibility == 0 {
+		t.DualFeasibility = defaults.DualFeasibility
+	}
+	if t.Pivot == 0 {
+		t.Pivot = defaults.Pivot
+	}
+	if t.Zero == 0 {
+		t.Zero = defaults.Zero
+	}
+	return t
+}
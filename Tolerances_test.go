@@ -0,0 +1,92 @@
+package sago
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLP_SetTolerances(t *testing.T) {
+	lp := NewLP()
+	assert.Equal(t, DefaultTolerances(), lp.GetTolerances())
+	lp.SetTolerances(Tolerances{Pivot: 1e-12})
+	expected := DefaultTolerances()
+	expected.Pivot = 1e-12
+	assert.Equal(t, expected, lp.GetTolerances())
+	assert.Equal(t, expected, lp.Copy().GetTolerances())
+}
+
+func TestLP_SetTolerances_Pivot(t *testing.T) {
+	for _, engine := range []int{TABLEAU, REVISED} {
+		// x <= 10 scaled down below EPSILON
+		lp := NewLP()
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+		lp.AddConstraintGeq(1e-10, 1e-11)
+		lp.SetEngine(engine)
+		assert.Equal(t, UNBOUNDED, lp.Optimize().Status)
+
+		lp.SetTolerances(Tolerances{Pivot: 1e-13, Zero: 1e-13})
+		result := lp.Optimize()
+		assert.Equal(t, OPTIMAL, result.Status)
+		assert.InDelta(t, 10, result.ObjectiveValue, 1e-6)
+
+		// B = (1e-11) is not singular to the sensitivity analysis either
+		report, err := lp.Sensitivity()
+		assert.NoError(t, err)
+		assert.InDelta(t, 1e11, report.ShadowPrices[0], 1)
+	}
+
+	for name, rule := range pivotRules() {
+		lp := NewLP()
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+		lp.AddConstraintGeq(1e-10, 1e-11)
+		lp.SetPivotRule(rule())
+		assert.Equal(t, UNBOUNDED, lp.Optimize().Status, name)
+		lp.SetTolerances(Tolerances{Pivot: 1e-13, Zero: 1e-13})
+		assert.Equal(t, OPTIMAL, lp.Optimize().Status, name)
+	}
+}
+
+func TestLP_SetTolerances_DualFeasibility(t *testing.T) {
+	for _, engine := range []int{TABLEAU, REVISED} {
+		// phase I makes x1 basic, which is optimal unless the tiny
+		// coefficient of x2 counts
+		lp := NewLP()
+		lp.SetObjectiveFunction(MAXIMIZE, 0, 0, 1e-11)
+		lp.AddConstraintGeq(1, 1, 1)
+		lp.SetEngine(engine)
+		lp.Optimize()
+		sol, _ := lp.Solution()
+		assert.Equal(t, []float64{1, 0}, sol[:2])
+
+		lp.SetTolerances(Tolerances{DualFeasibility: 1e-13, Zero: 1e-13})
+		lp.Optimize()
+		sol, _ = lp.Solution()
+		assert.Equal(t, []float64{0, 1}, sol[:2])
+	}
+}
+
+func TestLP_SetTolerances_PrimalFeasibility(t *testing.T) {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.AddConstraintLeq(1+1e-11, 1)
+	assert.True(t, lp.Feasible())
+
+	lp.SetTolerances(Tolerances{PrimalFeasibility: 1e-13})
+	assert.False(t, lp.Feasible())
+	assert.Equal(t, INFEASIBLE, lp.Optimize().Status)
+
+	// x = 1 - 1e-11 is at the upper limit 1 of the constraint x <= 1
+	lp = NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1)
+	lp.AddConstraintGeq(1-1e-11, 1)
+	lp.AddConstraintGeq(1, 1)
+	lp.Optimize()
+	side, err := lp.ActiveSide(1)
+	assert.NoError(t, err)
+	assert.Equal(t, ATUPPER, side)
+	lp.SetTolerances(Tolerances{PrimalFeasibility: 1e-13})
+	lp.Optimize()
+	side, _ = lp.ActiveSide(1)
+	assert.Equal(t, INACTIVE, side)
+}
@@ -0,0 +1,219 @@
+package sago
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Trace records the tableau before and after every iteration of the simplex
+//algorithm, for following the algorithm step by step
+type Trace struct {
+	Steps  []TraceStep
+	phase  int
+	before tableau // tableau at the start of the current iteration
+}
+
+//TraceStep is an iteration of the simplex algorithm. Column 0 of the tableaus
+//is b and row 0 the objective function; column j > 0 is the variable at index
+//j - 1, where the variables past those of the LP are the artificial variables
+//of phase I.
+type TraceStep struct {
+	Phase  int // PHASEI, PHASEII or DUALSIMPLEX
+	Before [][]float64
+	After  [][]float64
+	//Row and Column locate the pivot element in Before. Row is 0 if the
+	//variable of Column moved to its upper bound without a pivot.
+	Row    int
+	Column int
+}
+
+//SetTrace makes Optimize and Feasible record their iterations in trace, or stops
+//recording if trace is nil. The iterations of phase I show the tableau of the
+//auxiliary LP. While tracing, Optimize uses the TABLEAU engine. Copies of the
+//LP are not traced.
+func (lp *LP) SetTrace(trace *Trace) {
+	lp.trace = trace
+}
+
+// sets the phase reported to the callback and recorded in the trace
+func (lp *LP) setPhase(phase int) {
+	lp.control.setPhase(phase)
+	lp.trace.setPhase(phase)
+}
+
+func (t *Trace) setPhase(phase int) {
+	if t != nil {
+		t.phase = phase
+	}
+}
+
+// remembers the tableau at the start of an iteration
+func (t *Trace) begin(tab tableau) {
+	if t != nil {
+		t.before = tab.copy()
+	}
+}
+
+// records the iteration that pivoted on tab[row][col]
+func (t *Trace) record(tab tableau, row, col int) {
+	if t == nil || t.before == nil {
+		return
+	}
+	phase := t.phase
+	if phase == 0 {
+		phase = PHASEII
+	}
+	t.Steps = append(t.Steps, TraceStep{
+		Phase:  phase,
+		Before: t.before,
+		After:  tab.copy(),
+		Row:    row,
+		Column: col,
+	})
+	t.before = nil
+}
+
+//WriteText writes the steps of the trace as plain text tables, with the pivot
+//element in brackets
+func (t *Trace) WriteText(w io.Writer) error {
+	b := &strings.Builder{}
+	for i, step := range t.Steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "Iteration %d (%s): %s\n", i+1, phaseName(step.Phase), step.description(traceColumnName("x", step.Column)))
+		for k, tab := range [][][]float64{step.Before, step.After} {
+			if k == 1 {
+				b.WriteString("\n")
+			}
+			cells := step.cells(tab, "x", func(s string) string { return "[" + s + "]" })
+			widths := make([]int, len(cells[0]))
+			for _, row := range cells {
+				for j, s := range row {
+					if len(s) > widths[j] {
+						widths[j] = len(s)
+					}
+				}
+			}
+			for r, row := range cells {
+				for j, s := range row {
+					fmt.Fprintf(b, " %*s", widths[j], s)
+				}
+				b.WriteString("\n")
+				if r == 1 {
+					length := len(row)
+					for _, width := range widths {
+						length += width
+					}
+					b.WriteString(strings.Repeat("-", length) + "\n")
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//WriteMarkdown writes the steps of the trace as Markdown tables, with the pivot
+//element in bold
+func (t *Trace) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	for i, step := range t.Steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "**Iteration %d (%s):** %s\n", i+1, phaseName(step.Phase), step.description(traceColumnName("x", step.Column)))
+		for _, tab := range [][][]float64{step.Before, step.After} {
+			b.WriteString("\n")
+			cells := step.cells(tab, "x", func(s string) string { return "**" + s + "**" })
+			for r, row := range cells {
+				b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+				if r == 0 {
+					b.WriteString("|" + strings.Repeat(" ---: |", len(row)) + "\n")
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//WriteLaTeX writes the steps of the trace as LaTeX array environments, with the
+//pivot element boxed
+func (t *Trace) WriteLaTeX(w io.Writer) error {
+	b := &strings.Builder{}
+	for i, step := range t.Steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "\\paragraph{Iteration %d (%s)} %s\n", i+1, phaseName(step.Phase), step.description("$"+traceColumnName("x_", step.Column)+"$"))
+		b.WriteString("\\[\n")
+		for k, tab := range [][][]float64{step.Before, step.After} {
+			if k == 1 {
+				b.WriteString("\\longrightarrow\n")
+			}
+			cells := step.cells(tab, "x_", func(s string) string { return "\\boxed{" + s + "}" })
+			b.WriteString("\\begin{array}{r|" + strings.Repeat("r", len(cells[0])-1) + "}\n")
+			for r, row := range cells {
+				b.WriteString(strings.Join(row, " & ") + " \\\\\n")
+				if r <= 1 {
+					b.WriteString("\\hline\n")
+				}
+			}
+			b.WriteString("\\end{array}\n")
+		}
+		b.WriteString("\\]\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describes the pivot of the step, naming the entering variable name
+func (s TraceStep) description(name string) string {
+	if s.Row == 0 {
+		return name + " moves to its upper bound"
+	}
+	return fmt.Sprintf("%s enters the basis, pivot on row %d", name, s.Row)
+}
+
+// a header row of column names followed by the formatted tableau, with the
+// pivot element passed through highlight
+func (s TraceStep) cells(tab [][]float64, prefix string, highlight func(string) string) [][]string {
+	header := make([]string, len(tab[0]))
+	for j := range header {
+		header[j] = traceColumnName(prefix, j)
+	}
+	cells := [][]string{header}
+	for r, row := range tab {
+		formatted := make([]string, len(row))
+		for j, v := range row {
+			formatted[j] = formatTrace(v)
+			if r == s.Row && j == s.Column && r > 0 {
+				formatted[j] = highlight(formatted[j])
+			}
+		}
+		cells = append(cells, formatted)
+	}
+	return cells
+}
+
+func traceColumnName(prefix string, col int) string {
+	switch {
+	case col == 0:
+		return "b"
+	case prefix == "x_":
+		return "x_{" + strconv.Itoa(col) + "}"
+	}
+	return "x" + strconv.Itoa(col)
+}
+
+// formats a tableau entry, rounding values within EPSILON of 0 to 0
+func formatTrace(v float64) string {
+	if math.Abs(v) < EPSILON {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
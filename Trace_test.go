@@ -0,0 +1,142 @@
+package sago
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLP_SetTrace(t *testing.T) {
+	lp := sensitivityLP()
+	lp.AddConstraintLeq(1, 1, 1)
+	lp.SetEngine(REVISED)
+	trace := &Trace{}
+	lp.SetTrace(trace)
+	result := lp.Optimize()
+	assert.Equal(t, OPTIMAL, result.Status)
+	if !assert.Len(t, trace.Steps, result.Iterations) {
+		return
+	}
+	phase := PHASEI
+	for _, step := range trace.Steps {
+		// phase I comes first
+		if step.Phase != phase {
+			assert.Equal(t, PHASEII, step.Phase)
+			phase = PHASEII
+		}
+		assert.NotZero(t, step.Before[step.Row][step.Column])
+		for i, row := range step.After {
+			expected := 0.
+			if i == step.Row {
+				expected = 1
+			}
+			assert.InDelta(t, expected, row[step.Column], EPSILON)
+		}
+	}
+	assert.Equal(t, PHASEI, trace.Steps[0].Phase)
+	assert.Equal(t, PHASEII, phase)
+	last := trace.Steps[len(trace.Steps)-1]
+	assert.Equal(t, tableau(last.After), lp.tableau)
+
+	// warm start with the dual simplex algorithm
+	trace.Steps = nil
+	lp.AddConstraintGeq(1, 1)
+	lp.Optimize()
+	if assert.NotEmpty(t, trace.Steps) {
+		assert.Equal(t, DUALSIMPLEX, trace.Steps[0].Phase)
+	}
+
+	// copies are not traced
+	trace.Steps = nil
+	lp.Copy().Optimize()
+	assert.Empty(t, trace.Steps)
+	lp.SetTrace(nil)
+	lp.AddConstraintGeq(0, 0, 1)
+	lp.Optimize()
+	assert.Empty(t, trace.Steps)
+}
+
+func traceExample() *Trace {
+	lp := NewLP()
+	lp.SetObjectiveFunction(MAXIMIZE, 0, 1, 3)
+	lp.AddConstraintGeq(4, 2, 1)
+	trace := &Trace{}
+	lp.SetTrace(trace)
+	lp.Optimize()
+	return trace
+}
+
+func TestTrace_WriteText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, traceExample().WriteText(buf))
+	expected := `Iteration 1 (phase I): x1 enters the basis, pivot on row 1
+  b  x1 x2 x3 x4
+ -4  -2 -1 -1  0
+----------------
+  4 [2]  1  1  1
+
+ b  x1  x2  x3  x4
+ 0   0   0   0   1
+------------------
+ 2 [1] 0.5 0.5 0.5
+
+Iteration 2 (phase II): x2 enters the basis, pivot on row 1
+ b x1    x2  x3
+ 2  0  -2.5 0.5
+---------------
+ 2  1 [0.5] 0.5
+
+  b x1  x2 x3
+ 12  5   0  3
+-------------
+  4  2 [1]  1
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestTrace_WriteMarkdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, traceExample().WriteMarkdown(buf))
+	expected := `**Iteration 2 (phase II):** x2 enters the basis, pivot on row 1
+
+| b | x1 | x2 | x3 |
+| ---: | ---: | ---: | ---: |
+| 2 | 0 | -2.5 | 0.5 |
+| 2 | 1 | **0.5** | 0.5 |
+
+| b | x1 | x2 | x3 |
+| ---: | ---: | ---: | ---: |
+| 12 | 5 | 0 | 3 |
+| 4 | 2 | **1** | 1 |
+`
+	assert.True(t, strings.HasPrefix(buf.String(), "**Iteration 1 (phase I):** x1 enters the basis, pivot on row 1\n"))
+	assert.True(t, strings.HasSuffix(buf.String(), "\n"+expected), buf.String())
+}
+
+func TestTrace_WriteLaTeX(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, traceExample().WriteLaTeX(buf))
+	expected := `\paragraph{Iteration 2 (phase II)} $x_{2}$ enters the basis, pivot on row 1
+\[
+\begin{array}{r|rrr}
+b & x_{1} & x_{2} & x_{3} \\
+\hline
+2 & 0 & -2.5 & 0.5 \\
+\hline
+2 & 1 & \boxed{0.5} & 0.5 \\
+\end{array}
+\longrightarrow
+\begin{array}{r|rrr}
+b & x_{1} & x_{2} & x_{3} \\
+\hline
+12 & 5 & 0 & 3 \\
+\hline
+4 & 2 & \boxed{1} & 1 \\
+\end{array}
+\]
+`
+	assert.True(t, strings.HasPrefix(buf.String(), "\\paragraph{Iteration 1 (phase I)}"))
+	assert.True(t, strings.HasSuffix(buf.String(), "\n"+expected), buf.String())
+	assert.Equal(t, 2, strings.Count(buf.String(), "\\begin{array}{r|rrrr}"))
+}